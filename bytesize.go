@@ -0,0 +1,108 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 字节数，支持从"512MiB"、"10MB"、"1.5G"等格式解析
+type ByteSize uint64
+
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+	PB          = 1000 * TB
+
+	KiB ByteSize = 1024 * Byte
+	MiB          = 1024 * KiB
+	GiB          = 1024 * MiB
+	TiB          = 1024 * GiB
+	PiB          = 1024 * TiB
+)
+
+// 单位忽略大小写，KB、MB等为1000进制，KiB、MiB等及单字母K、M等为1024进制
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"pb":  PB,
+	"k":   KiB,
+	"m":   MiB,
+	"g":   GiB,
+	"t":   TiB,
+	"p":   PiB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+	"pib": PiB,
+}
+
+// 解析字节数
+// param: s 数字加单位，如"512MiB"、"10MB"、"1.5G"，无单位时为字节
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid byte size: %q", s)
+	}
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit: %q", s)
+	}
+	if n, err := strconv.ParseUint(s[:i], 10, 64); err == nil {
+		if n > math.MaxUint64/uint64(unit) {
+			return 0, fmt.Errorf("byte size overflow: %q", s)
+		}
+		return ByteSize(n) * unit, nil
+	}
+	f, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size: %q", s)
+	}
+	f *= float64(unit)
+	if f >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size overflow: %q", s)
+	}
+	return ByteSize(f), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// 使用能整除的最大1024进制单位，如512MiB
+func (b ByteSize) String() string {
+	units := []struct {
+		name string
+		size ByteSize
+	}{{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}}
+	for _, u := range units {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"reflect"
+	"sort"
+)
+
+// 属性变化回调
+// param: old 变化前key对应的值，不存在时为nil
+// param: new 变化后key对应的值，不存在时为nil
+type ChangeListener func(old, new interface{})
+
+type changeListener struct {
+	key      string
+	listener ChangeListener
+}
+
+// 注册属性变化回调，仅当key（A.B.C）下的值发生变化时回调，key为空时任意变化均回调
+// 回调在ReadValue完成后同步执行，此时已可通过Get/GetValue获得新值
+func (ctx *DefaultProperties) OnChange(key string, listener ChangeListener) {
+	ctx.listenerLock.Lock()
+	defer ctx.listenerLock.Unlock()
+
+	ctx.listeners = append(ctx.listeners, changeListener{
+		key:      canonicalKey(key),
+		listener: listener,
+	})
+}
+
+func (ctx *DefaultProperties) notifyChanges(old, new *Value) {
+	ctx.listenerLock.Lock()
+	listeners := make([]changeListener, len(ctx.listeners))
+	copy(listeners, ctx.listeners)
+	ctx.listenerLock.Unlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	changed := DiffValue(old, new)
+	if len(changed) == 0 {
+		return
+	}
+
+	var oldV, newV interface{}
+	if old != nil {
+		oldV = *old
+	}
+	if new != nil {
+		newV = *new
+	}
+	for _, l := range listeners {
+		if keyChanged(l.key, changed) {
+			o, oldOk := lookupValue(oldV, l.key)
+			n, newOk := lookupValue(newV, l.key)
+			// 列表整体比较，其中其他元素的变化不影响该key
+			if oldOk == newOk && reflect.DeepEqual(o, n) {
+				continue
+			}
+			l.listener(o, n)
+		}
+	}
+}
+
+func keyChanged(key string, changed []string) bool {
+	if key == "" {
+		return true
+	}
+	for _, c := range changed {
+		if isSubKey(c, key) || isSubKey(key, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// 比较两个Value的结构差异
+// return: 发生变化（新增、删除、修改）的key（A.B.C），map会逐层比较，其他类型整体比较
+func DiffValue(old, new *Value) []string {
+	var oldV, newV interface{}
+	if old != nil {
+		oldV = *old
+	}
+	if new != nil {
+		newV = *new
+	}
+	var ret []string
+	diffValue("", oldV, newV, &ret)
+	sort.Strings(ret)
+	return ret
+}
+
+func diffValue(prefix string, old, new interface{}, changed *[]string) {
+	oldMap, oldOk := old.(map[string]interface{})
+	newMap, newOk := new.(map[string]interface{})
+	if !oldOk || !newOk {
+		if !reflect.DeepEqual(old, new) {
+			*changed = append(*changed, prefix)
+		}
+		return
+	}
+
+	for k, o := range oldMap {
+		n, ok := newMap[k]
+		if !ok {
+			*changed = append(*changed, joinKey(prefix, k))
+			continue
+		}
+		diffValue(joinKey(prefix, k), o, n, changed)
+	}
+	for k := range newMap {
+		if _, ok := oldMap[k]; !ok {
+			*changed = append(*changed, joinKey(prefix, k))
+		}
+	}
+}
@@ -0,0 +1,834 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Decode遇到无法直接转换的类型（如实现了json.Unmarshaler的类型）时返回，调用方应使用Serialize/Deserialize
+var ErrDecodeUnsupported = errors.New("decode unsupported")
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+	ipType       = reflect.TypeOf(net.IP{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+)
+
+// 可以从字符串转换的类型，优先于json.Unmarshaler、encoding.TextUnmarshaler
+var stringDecoders = map[reflect.Type]func(s string) (interface{}, error){
+	durationType: func(s string) (interface{}, error) {
+		return time.ParseDuration(s)
+	},
+	byteSizeType: func(s string) (interface{}, error) {
+		return ParseByteSize(s)
+	},
+	timeType: func(s string) (interface{}, error) {
+		return time.Parse(time.RFC3339Nano, s)
+	},
+	urlType: func(s string) (interface{}, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	},
+	ipType: func(s string) (interface{}, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address: %q", s)
+		}
+		return ip, nil
+	},
+	ipNetType: func(s string) (interface{}, error) {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		return *n, nil
+	},
+}
+
+// 类型或其指针指向的类型可以从字符串转换
+func hasStringDecoder(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, ok := stringDecoders[t]
+	return ok
+}
+
+// 不区分格式的严格转换，用于未实现ValueDecoder的loader
+type strictDecoder struct{}
+
+func (strictDecoder) Decode(o interface{}, result interface{}) error {
+	return strictDecoder{}.decodeHooks(o, result, nil)
+}
+
+func (strictDecoder) decodeHooks(o interface{}, result interface{}, hooks []decodeHook) error {
+	d := valueDecoder{hooks: hooks}
+	return d.decode(o, result)
+}
+
+// 类型是否实现了json.Unmarshaler或encoding.TextUnmarshaler（stringDecoders中的类型及FigUnmarshaler除外）
+func hasUnmarshaler(t reflect.Type) bool {
+	if isFigUnmarshaler(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Ptr:
+		if _, ok := stringDecoders[t.Elem()]; ok {
+			return false
+		}
+	default:
+		t = reflect.PtrTo(t)
+	}
+	return t.Implements(jsonUnmarshalerType) || t.Implements(textUnmarshalerType)
+}
+
+func decodeError(key string, raw interface{}, t reflect.Type, err error) error {
+	return &DecodeError{
+		Key:  key,
+		Type: t,
+		Raw:  raw,
+		Err:  err,
+	}
+}
+
+// 使用反射将Value中的值转换为result，语义与encoding/json一致：
+// struct字段使用json tag或字段名（忽略大小写）匹配，number转换为整数时不能有小数部分且不能溢出
+type valueDecoder struct {
+	// 目标为string时将number、bool转换为字符串（与YamlLoader一致）
+	scalarToString bool
+	// 弱类型转换，见decodeWeak
+	weaklyTyped bool
+	// 注册的类型转换函数，优先于其他转换
+	hooks []decodeHook
+	// 类型不匹配时继续处理其他值，返回第一个错误
+	savedErr error
+}
+
+func (d *valueDecoder) decode(v interface{}, result interface{}) error {
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("result must be non-nil ptr, but get %T", result)
+	}
+	if err := d.decodeValue("", v, rv.Elem()); err != nil {
+		return err
+	}
+	return d.savedErr
+}
+
+// 保存类型不匹配的错误，不支持的类型仍然返回
+func (d *valueDecoder) saveError(err error) error {
+	if err == ErrDecodeUnsupported {
+		return err
+	}
+	if d.savedErr == nil {
+		d.savedErr = err
+	}
+	return nil
+}
+
+func (d *valueDecoder) decodeValue(key string, v interface{}, dst reflect.Value) error {
+	if ok, err := d.decodeCustom(key, v, dst); ok {
+		return err
+	}
+	if leaf, ok := leafValue(v, dst.Type()); ok {
+		v = leaf
+	}
+	if f, ok := stringDecoders[dst.Type()]; ok && v != nil {
+		switch o := v.(type) {
+		case string:
+			ret, err := f(strings.TrimSpace(o))
+			if err != nil {
+				return decodeError(key, v, dst.Type(), err)
+			}
+			dst.Set(reflect.ValueOf(ret))
+			return nil
+		case time.Time:
+			if dst.Type() == timeType {
+				dst.Set(reflect.ValueOf(o))
+				return nil
+			}
+		}
+		// Duration、ByteSize可以使用数字（纳秒、字节）
+		if dst.Type() != durationType && dst.Type() != byteSizeType {
+			return decodeError(key, v, dst.Type(), nil)
+		}
+	} else if hasUnmarshaler(dst.Type()) {
+		return ErrDecodeUnsupported
+	}
+	if v == nil {
+		switch dst.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+	if d.weaklyTyped {
+		if ok, err := d.decodeWeak(key, v, dst); ok {
+			return err
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.decodeValue(key, v, dst.Elem())
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return ErrDecodeUnsupported
+		}
+		o, err := normalizeValue(v)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(o))
+		return nil
+	case reflect.Bool:
+		if b, ok := v.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.String:
+		if s, ok := v.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+		if d.scalarToString {
+			if s, ok := scalarString(v); ok {
+				dst.SetString(s)
+				return nil
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := number(v); ok {
+			i, err := intValue(v)
+			if err == nil && dst.OverflowInt(i) {
+				err = errOverflow
+			}
+			if err != nil {
+				return decodeError(key, v, dst.Type(), err)
+			}
+			dst.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if _, ok := number(v); ok {
+			i, err := uintValue(v)
+			if err == nil && dst.OverflowUint(i) {
+				err = errOverflow
+			}
+			if err != nil {
+				return decodeError(key, v, dst.Type(), err)
+			}
+			dst.SetUint(i)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := number(v); ok {
+			f, err := floatValue(v)
+			if err == nil && dst.OverflowFloat(f) {
+				err = errOverflow
+			}
+			if err != nil {
+				return decodeError(key, v, dst.Type(), err)
+			}
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.Slice:
+		if l, ok := listValue(v); ok {
+			if dst.Type().Elem().Kind() == reflect.Uint8 {
+				// []byte使用base64编码
+				return ErrDecodeUnsupported
+			}
+			s := reflect.MakeSlice(dst.Type(), len(l), len(l))
+			for i := range l {
+				if err := d.decodeValue(indexKey(key, i), l[i], s.Index(i)); err != nil {
+					if err := d.saveError(err); err != nil {
+						return err
+					}
+				}
+			}
+			dst.Set(s)
+			return nil
+		}
+	case reflect.Array:
+		if l, ok := listValue(v); ok {
+			for i := 0; i < dst.Len(); i++ {
+				if i < len(l) {
+					if err := d.decodeValue(indexKey(key, i), l[i], dst.Index(i)); err != nil {
+						if err := d.saveError(err); err != nil {
+							return err
+						}
+					}
+				} else {
+					dst.Index(i).Set(reflect.Zero(dst.Type().Elem()))
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := mapValue(v); ok {
+			return d.decodeMap(key, m, dst)
+		}
+	case reflect.Struct:
+		if m, ok := mapValue(v); ok {
+			return d.decodeStruct(key, m, dst)
+		}
+	default:
+		return ErrDecodeUnsupported
+	}
+	if _, ok := v.(map[string]interface{}); !ok && !isPlainValue(v) {
+		return ErrDecodeUnsupported
+	}
+	return decodeError(key, v, dst.Type(), nil)
+}
+
+func (d *valueDecoder) decodeMap(key string, m map[string]interface{}, dst reflect.Value) error {
+	t := dst.Type()
+	kt := t.Key()
+	switch kt.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return ErrDecodeUnsupported
+	}
+	if pt := reflect.PtrTo(kt); pt.Implements(textUnmarshalerType) {
+		return ErrDecodeUnsupported
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(t))
+	}
+	for k, v := range m {
+		mk := reflect.New(kt).Elem()
+		switch kt.Kind() {
+		case reflect.String:
+			mk.SetString(k)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(k, 10, 64)
+			if err == nil && mk.OverflowInt(i) {
+				err = errOverflow
+			}
+			if err != nil {
+				if err := d.saveError(decodeError(joinKey(key, k), k, kt, err)); err != nil {
+					return err
+				}
+				continue
+			}
+			mk.SetInt(i)
+		default:
+			i, err := strconv.ParseUint(k, 10, 64)
+			if err == nil && mk.OverflowUint(i) {
+				err = errOverflow
+			}
+			if err != nil {
+				if err := d.saveError(decodeError(joinKey(key, k), k, kt, err)); err != nil {
+					return err
+				}
+				continue
+			}
+			mk.SetUint(i)
+		}
+		e := reflect.New(t.Elem()).Elem()
+		if err := d.decodeValue(joinKey(key, k), v, e); err != nil {
+			if err := d.saveError(err); err != nil {
+				return err
+			}
+			continue
+		}
+		dst.SetMapIndex(mk, e)
+	}
+	return nil
+}
+
+func (d *valueDecoder) decodeStruct(key string, m map[string]interface{}, dst reflect.Value) error {
+	fields := structFields(dst.Type())
+	for k, v := range m {
+		f := fields.find(k)
+		if f == nil {
+			continue
+		}
+		if f.quoted {
+			return ErrDecodeUnsupported
+		}
+		fv, ok := fieldByIndex(dst, f.index)
+		if !ok {
+			return ErrDecodeUnsupported
+		}
+		if err := d.decodeValue(joinKey(key, k), v, fv); err != nil {
+			if err := d.saveError(err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 按字段路径获取字段，路径中的nil指针自动创建
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+type field struct {
+	name   string
+	index  []int
+	tagged bool
+	// json tag中的",string"选项
+	quoted bool
+}
+
+type fieldList []field
+
+// 优先完全匹配，其次忽略大小写匹配
+func (fs fieldList) find(name string) *field {
+	for i := range fs {
+		if fs[i].name == name {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].name, name) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+var fieldCache sync.Map
+
+// 与encoding/json一致的字段列表：匿名struct字段展开，外层字段优先
+func structFields(t reflect.Type) fieldList {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(fieldList)
+	}
+	var ret fieldList
+	seen := map[string]bool{}
+	current := []field{{index: nil}}
+	types := []reflect.Type{t}
+	visited := map[reflect.Type]bool{}
+	for len(types) > 0 {
+		var nextFields []field
+		var nextTypes []reflect.Type
+		count := map[string]int{}
+		tags := map[string]int{}
+		var level fieldList
+		for n, st := range types {
+			if visited[st] {
+				continue
+			}
+			visited[st] = true
+			for i := 0; i < st.NumField(); i++ {
+				sf := st.Field(i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if sf.PkgPath != "" {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts := tag, ""
+				if i := strings.Index(tag, ","); i != -1 {
+					name, opts = tag[:i], tag[i+1:]
+				}
+				index := append(append([]int{}, current[n].index...), i)
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					nextFields = append(nextFields, field{index: index})
+					nextTypes = append(nextTypes, ft)
+					continue
+				}
+				quoted := false
+				for _, o := range strings.Split(opts, ",") {
+					if o == "string" {
+						quoted = true
+					}
+				}
+				tagged := name != ""
+				if name == "" {
+					name = sf.Name
+				}
+				count[name]++
+				if tagged {
+					tags[name]++
+				}
+				level = append(level, field{name: name, index: index, tagged: tagged, quoted: quoted})
+			}
+		}
+		for _, f := range level {
+			// 同一层级的同名字段：只有一个带tag时使用该字段，否则均忽略
+			if seen[f.name] {
+				continue
+			}
+			if count[f.name] > 1 && (tags[f.name] != 1 || !f.tagged) {
+				continue
+			}
+			ret = append(ret, f)
+		}
+		for name := range count {
+			seen[name] = true
+		}
+		current = nextFields
+		types = nextTypes
+	}
+	fieldCache.Store(t, ret)
+	return ret
+}
+
+// 转换为与json反序列化到interface{}一致的格式，数字按exactNumber转换
+func normalizeValue(v interface{}) (interface{}, error) {
+	switch o := v.(type) {
+	case nil, string, bool, int64, uint64, float64:
+		return v, nil
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(o))
+		for k, v := range o {
+			n, err := normalizeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = n
+		}
+		return ret, nil
+	}
+	if n, ok := exactNumber(v); ok {
+		return n, nil
+	}
+	if l, ok := listValue(v); ok {
+		ret := make([]interface{}, len(l))
+		for i := range l {
+			n, err := normalizeValue(l[i])
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = n
+		}
+		return ret, nil
+	}
+	if m, ok := mapValue(v); ok {
+		return normalizeValue(m)
+	}
+	return nil, ErrDecodeUnsupported
+}
+
+// 深度复制Value，数字按exactNumber转换，其余值保持不变
+func settingsValue(v interface{}) interface{} {
+	switch o := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(o))
+		for k, v := range o {
+			ret[k] = settingsValue(v)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(o))
+		for i := range o {
+			ret[i] = settingsValue(o[i])
+		}
+		return ret
+	}
+	if n, ok := exactNumber(v); ok {
+		return n
+	}
+	return v
+}
+
+// 数字转换为interface{}时的统一格式，不丢失精度：
+// 整数转换为int64，超出int64范围的非负整数转换为uint64，其余数字转换为float64
+func exactNumber(v interface{}) (interface{}, bool) {
+	switch o := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(o), 10, 64); err == nil {
+			return i, true
+		}
+		if u, err := strconv.ParseUint(string(o), 10, 64); err == nil {
+			return u, true
+		}
+		f, err := strconv.ParseFloat(string(o), 64)
+		return f, err == nil || isRangeError(err)
+	case bool, string:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return rv.Uint(), true
+		}
+		return int64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return nil, false
+}
+
+// v是否为数字
+// return: 数字的近似值（json.Number、大整数可能丢失精度），精确转换使用intValue、uintValue、floatValue
+func number(v interface{}) (float64, bool) {
+	switch o := v.(type) {
+	case float64:
+		return o, true
+	case json.Number:
+		f, err := strconv.ParseFloat(string(o), 64)
+		return f, err == nil || isRangeError(err)
+	case bool, string:
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// 2^63，float64可以精确表示
+const maxInt64Float = float64(1 << 63)
+
+// 精确转换为int64，json.Number按原始文本解析
+func intValue(v interface{}) (int64, error) {
+	if n, ok := v.(json.Number); ok {
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err == nil {
+			return i, nil
+		}
+		if isRangeError(err) {
+			return 0, errOverflow
+		}
+		// 1e3、1.0等可以表示为整数的格式
+		f, err := floatValue(n)
+		if err != nil {
+			return 0, err
+		}
+		return floatToInt(f)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, errOverflow
+		}
+		return int64(rv.Uint()), nil
+	}
+	f, _ := number(v)
+	return floatToInt(f)
+}
+
+func floatToInt(f float64) (int64, error) {
+	if f != math.Trunc(f) {
+		return 0, errNotInteger
+	}
+	if f < -maxInt64Float || f >= maxInt64Float {
+		return 0, errOverflow
+	}
+	return int64(f), nil
+}
+
+// 精确转换为uint64，json.Number按原始文本解析
+func uintValue(v interface{}) (uint64, error) {
+	if n, ok := v.(json.Number); ok {
+		i, err := strconv.ParseUint(string(n), 10, 64)
+		if err == nil {
+			return i, nil
+		}
+		if isRangeError(err) {
+			return 0, errOverflow
+		}
+		f, err := floatValue(n)
+		if err != nil {
+			return 0, err
+		}
+		return floatToUint(f)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return 0, errOverflow
+		}
+		return uint64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	}
+	f, _ := number(v)
+	return floatToUint(f)
+}
+
+func floatToUint(f float64) (uint64, error) {
+	if f != math.Trunc(f) {
+		return 0, errNotInteger
+	}
+	if f < 0 || f >= 2*maxInt64Float {
+		return 0, errOverflow
+	}
+	return uint64(f), nil
+}
+
+func floatValue(v interface{}) (float64, error) {
+	if n, ok := v.(json.Number); ok {
+		f, err := strconv.ParseFloat(string(n), 64)
+		if isRangeError(err) {
+			return 0, errOverflow
+		}
+		return f, err
+	}
+	f, _ := number(v)
+	return f, nil
+}
+
+func isRangeError(err error) bool {
+	var ne *strconv.NumError
+	return errors.As(err, &ne) && ne.Err == strconv.ErrRange
+}
+
+func listValue(v interface{}) ([]interface{}, bool) {
+	if l, ok := v.([]interface{}); ok {
+		return l, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	ret := make([]interface{}, rv.Len())
+	for i := range ret {
+		ret[i] = rv.Index(i).Interface()
+	}
+	return ret, true
+}
+
+func mapValue(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	ret := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		ret[iter.Key().String()] = iter.Value().Interface()
+	}
+	return ret, true
+}
+
+// Value中可以直接转换的值类型
+func isPlainValue(v interface{}) bool {
+	if _, ok := number(v); ok {
+		return true
+	}
+	switch v.(type) {
+	case string, bool:
+		return true
+	}
+	_, ok := listValue(v)
+	return ok
+}
+
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case map[string]interface{}:
+		return "map"
+	}
+	if _, ok := number(v); ok {
+		return "number"
+	}
+	if _, ok := listValue(v); ok {
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// 与YamlLoader（先转换为json再反序列化）将number、bool转换为字符串的格式一致
+func scalarString(v interface{}) (string, bool) {
+	switch o := v.(type) {
+	case bool:
+		return strconv.FormatBool(o), true
+	case json.Number:
+		if _, err := strconv.ParseInt(string(o), 10, 64); err == nil {
+			return string(o), true
+		}
+		if _, err := strconv.ParseUint(string(o), 10, 64); err == nil {
+			return string(o), true
+		}
+		f, _ := number(o)
+		return scalarString(f)
+	case float64:
+		if o == math.Trunc(o) && math.Abs(o) < 1e21 {
+			return strconv.FormatFloat(o, 'f', -1, 64), true
+		}
+		return strconv.FormatFloat(o, 'g', -1, 32), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 32), true
+	}
+	return "", false
+}
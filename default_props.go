@@ -12,8 +12,9 @@ import (
 	"fmt"
 	"io"
 	"reflect"
-	"strings"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"text/template"
 )
 
@@ -22,14 +23,35 @@ type Value = map[string]interface{}
 type Opt func(ctx *DefaultProperties) error
 
 type DefaultProperties struct {
+	// 最近发布的快照的属性值，只读，仅为兼容保留
+	// 修改操作会替换该字段但不加锁，与ReadValue、Set等并发读取不安全；直接赋值或修改其内容不会生效
+	//
+	// Deprecated: 使用AllSettings获得属性值的拷贝
 	Value *Value
 	Env   map[string]string
 
 	reader ValueReader
 	loader ValueLoader
 
-	cache map[string]interface{}
-	lock  sync.RWMutex
+	envFiles    []string
+	envOverride bool
+	overlay     *envOverlay
+
+	// GetValue使用弱类型转换
+	weaklyTyped bool
+	// 注册的类型转换函数
+	hooks []decodeHook
+
+	sourceName string
+	sourceFile string
+
+	// 当前快照*snapshot，读取时无需加锁
+	current atomic.Value
+	// 修改操作（ReadValue、Set等）之间互斥
+	lock sync.Mutex
+
+	listeners    []changeListener
+	listenerLock sync.Mutex
 }
 
 var Default Properties = New()
@@ -39,7 +61,6 @@ func New(opts ...Opt) *DefaultProperties {
 		Value:  nil,
 		reader: NewYamlReader(),
 		loader: NewYamlLoader(),
-		cache:  map[string]interface{}{},
 	}
 
 	for _, opt := range opts {
@@ -67,6 +88,16 @@ func SetValueLoader(l ValueLoader) Opt {
 	}
 }
 
+// 配置.env文件，在模板处理前与进程环境变量叠加
+// param: override 为true时.env文件中的值覆盖进程环境变量，否则进程环境变量优先
+// param: files .env文件路径，后面的文件优先，不存在的文件被忽略
+func SetEnvFiles(override bool, files ...string) Opt {
+	return func(ctx *DefaultProperties) error {
+		ctx.SetEnvFiles(override, files...)
+		return nil
+	}
+}
+
 func SetValue(r io.Reader) Opt {
 	return func(ctx *DefaultProperties) error {
 		return ctx.ReadValue(r)
@@ -81,100 +112,144 @@ func (ctx *DefaultProperties) SetValueLoader(l ValueLoader) {
 	ctx.loader = l
 }
 
+func (ctx *DefaultProperties) SetEnvFiles(override bool, files ...string) {
+	ctx.envOverride = override
+	ctx.envFiles = files
+}
+
 func (ctx *DefaultProperties) ReadValue(r io.Reader) error {
+	old, v, err := ctx.readValue(r)
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		ctx.notifyChanges(old, v)
+	}
+	return nil
+}
+
+func (ctx *DefaultProperties) readValue(r io.Reader) (*Value, *Value, error) {
 	ctx.lock.Lock()
 	defer ctx.lock.Unlock()
 
-	ctx.cache = map[string]interface{}{}
-	ctx.Env = GetEnvs()
+	env, err := GetEnvsWithFiles(ctx.envOverride, ctx.envFiles...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx.Env = env
 
 	if ctx.reader != nil {
 		r, err := ctx.ExecTemplate(r)
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+		var v *Value
+		var pos map[string]Position
+		if pr, ok := ctx.reader.(PositionReader); ok {
+			v, pos, err = pr.ReadWithPosition(r)
+		} else {
+			v, err = ctx.reader.Read(r)
 		}
-		v, err := ctx.reader.Read(r)
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+		prov := newProvenance(v, pos, ctx.source())
+		if ctx.overlay != nil {
+			for key, name := range ctx.overlay.apply(*v, env) {
+				o, _ := lookupValue(map[string]interface{}(*v), key)
+				prov.replace(key, o, Source{Name: sourceEnv + name})
+			}
 		}
 
-		ctx.Value = v
+		// 仅在模板处理及解析均成功后才替换Value，保证重新加载失败时原配置仍然可用
+		old := ctx.load().value
+		ctx.publish(v, prov)
+		return old, v, nil
 	}
-	return nil
+	return nil, nil, nil
 }
 
-// A.B.C
+// A.B.C，支持Servers[0].Host、Headers["X-Trace-Id"]、max-conn及a\.b，语法见parseKey
 func (ctx *DefaultProperties) Get(key string, defaultValue string) string {
-	//if key == "" {
-	//	return defaultValue
-	//}
-
-	ctx.lock.Lock()
-	defer ctx.lock.Unlock()
-
-	if v, ok := ctx.cache[key]; ok {
-		if ret, ok := v.(string); ok {
-			return ret
-		}
+	s := ctx.load()
+	if v, ok := s.cache.Load(key); ok {
+		return v.(string)
 	}
 
-	tempKey := "{{ ." + key + "}}"
-	tpl, ok := template.New("").Option("missingkey=error").Parse(tempKey)
-	if ok != nil {
-		logf("key: %s not found(parse error)", key)
-		return defaultValue
-	}
-	b := strings.Builder{}
-	err := tpl.Execute(&b, ctx.Value)
+	v, err := s.lookup(key)
 	if err != nil {
 		return defaultValue
 	}
-
-	ret := b.String()
-	ctx.cache[key] = ret
+	if leaf, ok := leafValue(v, nil); ok {
+		v = leaf
+	}
+	ret := noValue
+	if v != nil {
+		ret = fmt.Sprint(v)
+	}
+	s.cache.Store(key, ret)
 	return ret
 }
 
-// 依赖于ValueReader的序列化和反序列化方式
+// ValueLoader实现了ValueDecoder时直接转换，否则依赖于ValueLoader的序列化和反序列化方式
+// return: key不存在时返回ErrKeyNotFound，key语法错误时返回*ParseError，转换失败时返回*DecodeError
 func (ctx *DefaultProperties) GetValue(key string, result interface{}) error {
-	//if key == "" {
-	//	return fmt.Errorf("key is empty")
-	//}
-	ctx.lock.Lock()
-	defer ctx.lock.Unlock()
+	return ctx.getValue(key, result, ctx.weaklyTyped)
+}
 
-	if v, ok := ctx.cache[key]; ok {
-		if ret, ok := v.(string); ok {
-			err := ctx.loader.Deserialize(ret, result)
-			if err != nil {
-				return fmt.Errorf("Unmarshal from cache error: %s, data: %s ", err.Error(), ret)
+func (ctx *DefaultProperties) getValue(key string, result interface{}, weak bool) error {
+	s := ctx.load()
+	v, err := s.lookup(key)
+	if err != nil {
+		return err
+	}
+	cacheKey := key
+	if leaf, ok := leafValue(v, resultType(result)); ok {
+		v, cacheKey = leaf, joinPath(key, PropertiesLeafKey)
+	}
+	d, ok := ctx.loader.(ValueDecoder)
+	if weak {
+		d, ok = weakDecoder{}, true
+	} else if t := resultType(result); !ok && (hasStringDecoder(t) || isFigUnmarshaler(t) || ctx.hasDecodeHook(t)) {
+		// Duration、URL、FigUnmarshaler等类型与loader的序列化格式无关
+		d, ok = strictDecoder{}, true
+	}
+	if ok {
+		var err error
+		if hd, ok := d.(hookDecoder); ok {
+			err = hd.decodeHooks(v, result, ctx.hooks)
+		} else {
+			err = d.Decode(v, result)
+		}
+		if err != ErrDecodeUnsupported {
+			var de *DecodeError
+			if errors.As(err, &de) {
+				de.Key = joinPath(key, de.Key)
 			}
-			return nil
+			return err
 		}
 	}
 
-	tempKey := "{{ load_value ." + key + "}}"
-	tpl, ok := template.New("").Option("missingkey=error").Funcs(template.FuncMap{
-		"load_value": ctx.loader.Serialize,
-	}).Parse(tempKey)
-	if ok != nil {
-		return fmt.Errorf("key: %s not found(parse error)", key)
-	}
-	b := bytes.NewBuffer(nil)
-	err := tpl.Execute(b, ctx.Value)
-	if err != nil {
-		return fmt.Errorf("load from template failed: err: %s data: %s", err.Error(), b.String())
+	var data string
+	if ret, ok := s.valueCache.Load(cacheKey); ok {
+		data = ret.(string)
+	} else {
+		data, err = ctx.loader.Serialize(v)
+		if err != nil {
+			return decodeError(key, v, resultType(result), err)
+		}
+		s.valueCache.Store(cacheKey, data)
 	}
-
-	data := b.String()
-	ctx.cache[key] = data
 	err = ctx.loader.Deserialize(data, result)
 	if err != nil {
-		return fmt.Errorf("Unmarshal error: %s, data: %s ", err.Error(), b.String())
+		return decodeError(key, v, resultType(result), err)
 	}
 	return nil
 }
 
+// 与原模板实现输出nil的格式保持一致
+const noValue = "<no value>"
+
 func (ctx *DefaultProperties) ExecTemplate(r io.Reader) (io.Reader, error) {
 	buf := bytes.NewBuffer(nil)
 
@@ -246,21 +321,140 @@ func NewJsonLoader() *JsonLoader {
 }
 
 func (v *JsonReader) Read(r io.Reader) (*Value, error) {
+	ret, _, err := v.read(r, false)
+	return ret, err
+}
+
+func (v *JsonReader) ReadWithPosition(r io.Reader) (*Value, map[string]Position, error) {
+	return v.read(r, true)
+}
+
+func (v *JsonReader) read(r io.Reader, withPosition bool) (*Value, map[string]Position, error) {
 	buf := bytes.NewBuffer(nil)
 
 	_, err := io.Copy(buf, r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	ret := Value{}
 	logf("value: %s\n", buf.String())
-	err = json.Unmarshal(buf.Bytes(), &ret)
+	err = unmarshalJson(buf.Bytes(), &ret)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if !withPosition {
+		return &ret, nil, nil
+	}
+	pos := map[string]Position{}
+	p := &jsonPositionWalker{
+		data:  buf.Bytes(),
+		dec:   json.NewDecoder(bytes.NewReader(buf.Bytes())),
+		lines: newLineIndex(buf.Bytes()),
+		ret:   pos,
+	}
+	// 位置信息仅用于Explain，解析失败时忽略
+	if err := p.walk("", Position{}); err != nil {
+		pos = nil
+	}
+	return &ret, pos, nil
+}
+
+// 数字使用json.Number保存原始文本，避免大整数转换为float64后丢失精度
+func unmarshalJson(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid data after top-level value")
+	}
+	return nil
+}
+
+// 使用json.Decoder的token流及InputOffset计算每个叶子节点的位置
+type jsonPositionWalker struct {
+	data  []byte
+	dec   *json.Decoder
+	lines lineIndex
+	ret   map[string]Position
+}
+
+func (w *jsonPositionWalker) next() Position {
+	off := int(w.dec.InputOffset())
+	for off < len(w.data) {
+		c := w.data[off]
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' && c != ',' && c != ':' {
+			break
+		}
+		off++
+	}
+	return w.lines.position(off)
+}
+
+func (w *jsonPositionWalker) walk(key string, pos Position) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		if key != "" {
+			w.ret[key] = pos
+		}
+		return nil
 	}
 
-	return &ret, nil
+	i := 0
+	for ; w.dec.More(); i++ {
+		p := w.next()
+		if d == '{' {
+			k, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			err = w.walk(joinKey(key, k.(string)), p)
+			if err != nil {
+				return err
+			}
+		} else {
+			err := w.walk(indexKey(key, i), p)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if i == 0 && key != "" {
+		w.ret[key] = pos
+	}
+	// '}'或']'
+	_, err = w.dec.Token()
+	return err
+}
+
+// 记录每行起始位置，用于将偏移量转换为行列号
+type lineIndex []int
+
+func newLineIndex(data []byte) lineIndex {
+	ret := lineIndex{0}
+	for i, c := range data {
+		if c == '\n' {
+			ret = append(ret, i+1)
+		}
+	}
+	return ret
+}
+
+func (l lineIndex) position(offset int) Position {
+	i := sort.Search(len(l), func(i int) bool {
+		return l[i] > offset
+	}) - 1
+	return Position{
+		Line:   i + 1,
+		Column: offset - l[i] + 1,
+	}
 }
 
 func (v *JsonLoader) Serialize(o interface{}) (string, error) {
@@ -278,3 +472,12 @@ func (v *JsonLoader) Deserialize(value string, result interface{}) error {
 	//}
 	return json.Unmarshal([]byte(value), result)
 }
+
+func (v *JsonLoader) Decode(o interface{}, result interface{}) error {
+	return v.decodeHooks(o, result, nil)
+}
+
+func (v *JsonLoader) decodeHooks(o interface{}, result interface{}, hooks []decodeHook) error {
+	d := valueDecoder{hooks: hooks}
+	return d.decode(o, result)
+}
@@ -0,0 +1,363 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// 获得环境变量，依次叠加files中的.env文件（后面的文件优先），不存在的文件被忽略
+// param: override 为true时.env文件中的值覆盖进程环境变量，否则进程环境变量优先
+// return: 叠加后的环境变量
+func GetEnvsWithFiles(override bool, files ...string) (map[string]string, error) {
+	procEnv := GetEnvs()
+	fileEnv := map[string]string{}
+	lookup := func(key string) (string, bool) {
+		if !override {
+			if v, ok := procEnv[key]; ok {
+				return v, true
+			}
+		}
+		if v, ok := fileEnv[key]; ok {
+			return v, true
+		}
+		v, ok := procEnv[key]
+		return v, ok
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		// 直接写入fileEnv，使文件中的变量引用与最终生效的值一致
+		err = parseDotenv(string(data), fileEnv, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+	}
+
+	ret := make(map[string]string, len(procEnv)+len(fileEnv))
+	for k, v := range procEnv {
+		ret[k] = v
+	}
+	for k, v := range fileEnv {
+		if _, ok := procEnv[k]; ok && !override {
+			continue
+		}
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+// 查找属性使用的环境变量
+type envLookuper interface {
+	lookupEnv(key string) (string, bool)
+}
+
+// 查找props使用的环境变量（包括SetEnvFiles加载的.env文件），props没有环境变量时查找进程环境变量
+func lookupEnv(props Properties, key string) (string, bool) {
+	if l, ok := props.(envLookuper); ok {
+		return l.lookupEnv(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// 使用当前快照的环境变量，无需加锁；尚未读取属性时查找进程环境变量
+func (ctx *DefaultProperties) lookupEnv(key string) (string, bool) {
+	env := ctx.load().env
+	if env == nil {
+		return os.LookupEnv(key)
+	}
+	v, ok := env[key]
+	return v, ok
+}
+
+func (p *subProperties) lookupEnv(key string) (string, bool) {
+	return lookupEnv(p.parent, key)
+}
+
+func (p *weakProperties) lookupEnv(key string) (string, bool) {
+	return lookupEnv(p.Properties, key)
+}
+
+// 按优先级依次查找各属性的环境变量
+func (p *mergedProperties) lookupEnv(key string) (string, bool) {
+	for _, prop := range p.props {
+		if v, ok := lookupEnv(prop, key); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// 读取.env文件：
+// * 支持export前缀及#注释
+// * 单引号内的值原样保留
+// * 双引号内的值支持\n \r \t \" \\ \$转义，可跨行
+// * 双引号及无引号的值支持$VAR、${VAR}、${VAR:-default}展开，先查找文件内已定义的变量，再使用lookup查找
+// param: lookup 查找文件外的变量，可为nil
+func ReadDotenv(r io.Reader, lookup func(key string) (string, bool)) (map[string]string, error) {
+	buf := bytes.NewBuffer(nil)
+	_, err := io.Copy(buf, r)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]string{}
+	err = parseDotenv(buf.String(), ret, func(key string) (string, bool) {
+		if v, ok := ret[key]; ok {
+			return v, true
+		}
+		if lookup != nil {
+			return lookup(key)
+		}
+		return "", false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// 解析src并将变量写入env，变量展开时使用lookup查找
+func parseDotenv(src string, env map[string]string, lookup func(key string) (string, bool)) error {
+	p := &dotenvParser{
+		src:    src,
+		line:   1,
+		lookup: lookup,
+	}
+	for {
+		key, value, ok, err := p.next()
+		if err != nil {
+			return fmt.Errorf("line %d: %s", p.line, err.Error())
+		}
+		if !ok {
+			return nil
+		}
+		env[key] = value
+	}
+}
+
+type dotenvParser struct {
+	src string
+	pos int
+	// 当前行号，从1开始
+	line   int
+	lookup func(key string) (string, bool)
+}
+
+func (p *dotenvParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) next() (string, string, bool, error) {
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return "", "", false, nil
+		}
+		switch p.peek() {
+		case '\n':
+			p.pos++
+			p.line++
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+		break
+	}
+
+	key := p.readKey()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.readKey()
+	}
+	if key == "" {
+		return "", "", false, fmt.Errorf("invalid key")
+	}
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return "", "", false, fmt.Errorf("key %s: expect '='", key)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var value string
+	var err error
+	switch p.peek() {
+	case '\'':
+		value, err = p.readSingleQuoted()
+	case '"':
+		value, err = p.readDoubleQuoted()
+	default:
+		value = p.readUnquoted()
+	}
+	if err != nil {
+		return "", "", false, fmt.Errorf("key %s: %s", key, err.Error())
+	}
+
+	p.skipSpaces()
+	switch p.peek() {
+	case 0, '\n':
+	case '#':
+		p.skipLine()
+	default:
+		return "", "", false, fmt.Errorf("key %s: unexpected character %q after value", key, p.peek())
+	}
+	return key, value, true, nil
+}
+
+func (p *dotenvParser) readKey() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == '_' || c == '.' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *dotenvParser) readSingleQuoted() (string, error) {
+	p.pos++
+	end := strings.IndexByte(p.src[p.pos:], '\'')
+	if end == -1 {
+		return "", fmt.Errorf("unterminated single quote")
+	}
+	value := p.src[p.pos : p.pos+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *dotenvParser) readDoubleQuoted() (string, error) {
+	p.pos++
+	buf := strings.Builder{}
+	for !p.eof() {
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return buf.String(), nil
+		case '\\':
+			p.pos++
+			switch p.peek() {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case '"', '\\', '$':
+				buf.WriteByte(p.peek())
+			default:
+				buf.WriteByte('\\')
+				buf.WriteByte(p.peek())
+			}
+			p.pos++
+		case '$':
+			n, v := expandVar(p.src[p.pos:], p.lookup)
+			buf.WriteString(v)
+			p.pos += n
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			buf.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated double quote")
+}
+
+func (p *dotenvParser) readUnquoted() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		// 空白后的#为注释
+		if p.peek() == '#' && p.pos > start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+
+	buf := strings.Builder{}
+	for i := 0; i < len(raw); {
+		if raw[i] == '$' {
+			n, v := expandVar(raw[i:], p.lookup)
+			buf.WriteString(v)
+			i += n
+			continue
+		}
+		buf.WriteByte(raw[i])
+		i++
+	}
+	return buf.String()
+}
+
+// 展开s开头的变量引用（s[0]为'$'）
+// return: 消耗的字符数及展开后的值，不是合法的变量引用时原样返回'$'
+func expandVar(s string, lookup func(key string) (string, bool)) (int, string) {
+	if len(s) < 2 {
+		return 1, "$"
+	}
+	if s[1] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end == -1 {
+			return 1, "$"
+		}
+		name := s[2:end]
+		defaultValue := ""
+		if i := strings.Index(name, ":-"); i != -1 {
+			name, defaultValue = name[:i], name[i+2:]
+		}
+		if v, ok := lookup(name); ok && v != "" {
+			return end + 1, v
+		}
+		return end + 1, defaultValue
+	}
+
+	n := 1
+	for n < len(s) && (s[n] == '_' || (s[n] >= 'a' && s[n] <= 'z') || (s[n] >= 'A' && s[n] <= 'Z') || (n > 1 && s[n] >= '0' && s[n] <= '9')) {
+		n++
+	}
+	if n == 1 {
+		return 1, "$"
+	}
+	v, _ := lookup(s[1:n])
+	return n, v
+}
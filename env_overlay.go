@@ -0,0 +1,160 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultEnvOverlaySeparator = "_"
+)
+
+type envOverlay struct {
+	prefix    string
+	separator string
+}
+
+// 使用环境变量覆盖已加载的属性值，如APP_DATASOURCES_DEFAULT_MAXCONN=10覆盖DataSources.default.MaxConn
+// key的匹配忽略大小写及'-'、'_'，只覆盖已存在的key，列表元素使用下标匹配，如APP_SERVERS_0_HOST
+// param: prefix 环境变量前缀，为空时匹配所有环境变量
+// param: separator 分隔符，为空时使用"_"
+func SetEnvOverlay(prefix, separator string) Opt {
+	return func(ctx *DefaultProperties) error {
+		ctx.SetEnvOverlay(prefix, separator)
+		return nil
+	}
+}
+
+func (ctx *DefaultProperties) SetEnvOverlay(prefix, separator string) {
+	if separator == "" {
+		separator = DefaultEnvOverlaySeparator
+	}
+	ctx.overlay = &envOverlay{
+		prefix:    prefix,
+		separator: separator,
+	}
+}
+
+// return: 被覆盖的key及对应的环境变量名
+func (o *envOverlay) apply(v Value, env map[string]string) map[string]string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ret := map[string]string{}
+	for _, k := range keys {
+		name := k
+		if o.prefix != "" {
+			px := o.prefix + o.separator
+			if len(name) <= len(px) || !strings.EqualFold(name[:len(px)], px) {
+				continue
+			}
+			name = name[len(px):]
+		}
+		key, ok := overlayValue(map[string]interface{}(v), "", strings.Split(name, o.separator), o.separator, env[k])
+		if ok {
+			ret[key] = k
+		}
+	}
+	return ret
+}
+
+func overlayValue(node interface{}, prefix string, segs []string, separator string, value string) (string, bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		// 优先匹配最长的key，以支持本身包含分隔符的key
+		for j := len(segs); j > 0; j-- {
+			name := normalizeEnvKey(strings.Join(segs[:j], separator))
+			for _, k := range keys {
+				if normalizeEnvKey(k) != name {
+					continue
+				}
+				if j == len(segs) {
+					n[k] = convertEnvValue(n[k], value)
+					return joinKey(prefix, k), true
+				}
+				if key, ok := overlayValue(n[k], joinKey(prefix, k), segs[j:], separator, value); ok {
+					return key, true
+				}
+			}
+		}
+	case []interface{}:
+		i, err := strconv.Atoi(segs[0])
+		if err != nil || i < 0 || i >= len(n) {
+			return "", false
+		}
+		if len(segs) == 1 {
+			n[i] = convertEnvValue(n[i], value)
+			return indexKey(prefix, i), true
+		}
+		return overlayValue(n[i], indexKey(prefix, i), segs[1:], separator, value)
+	}
+	return "", false
+}
+
+func normalizeEnvKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == '.' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// 按原值的类型转换环境变量的值，转换失败时使用字符串
+func convertEnvValue(old interface{}, value string) interface{} {
+	if old == nil {
+		return value
+	}
+	if _, ok := old.(json.Number); ok {
+		if isJsonNumber(value) {
+			return json.Number(value)
+		}
+		return value
+	}
+	switch reflect.TypeOf(old).Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case reflect.Map, reflect.Slice:
+		// 使用yaml（兼容json）格式解析
+		var v interface{}
+		if err := unmarshalYaml([]byte(value), &v); err == nil && v != nil &&
+			reflect.TypeOf(v).Kind() == reflect.TypeOf(old).Kind() {
+			return v
+		}
+	}
+	return value
+}
+
+func isJsonNumber(s string) bool {
+	return s != "" && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) && json.Valid([]byte(s))
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// key不存在，使用errors.Is判断
+var ErrKeyNotFound = errors.New("key not found")
+
+// key语法错误
+type ParseError struct {
+	// 解析的key
+	Key string
+	// 出错的位置（字节偏移）
+	Offset int
+	// 错误描述
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("key: %s parse error at %d: %s", e.Key, e.Offset, e.Msg)
+}
+
+// 属性值无法转换为目标类型
+type DecodeError struct {
+	// 转换失败的属性的完整key
+	Key string
+	// 目标类型
+	Type reflect.Type
+	// 属性的原始值
+	Raw interface{}
+	// 失败原因，类型不匹配时为nil
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	raw := fmt.Sprint(e.Raw)
+	if s, ok := e.Raw.(string); ok {
+		raw = strconv.Quote(s)
+	}
+	msg := fmt.Sprintf("key: %s cannot decode %s %s into %v", e.Key, valueKind(e.Raw), raw, e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Fill填充field失败
+type FieldError struct {
+	// field路径，如Servers[0].Port
+	Field string
+	// 属性的完整key
+	Key string
+	// 失败原因，可能为ErrKeyNotFound（仅FillStrict）、*DecodeError等
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field: %s key: %s: %v", e.Field, e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	errNotInteger = errors.New("not an integer")
+	errOverflow   = errors.New("overflow")
+)
+
+func keyNotFound(key string) error {
+	return fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+}
+
+func parseError(key string, offset int, format string, args ...interface{}) error {
+	return &ParseError{
+		Key:    key,
+		Offset: offset,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// 填充对象指针指向的类型
+func resultType(result interface{}) reflect.Type {
+	t := reflect.TypeOf(result)
+	if t != nil && t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,528 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 递归填充struct：
+// 带有tag的struct、struct指针按层级填充，前缀为field的key，figPx在当前层级内生效；
+// []Struct、map[string]Struct按元素填充；未设置tag的匿名struct的field提升到当前层级
+type filler struct {
+	prop       Properties
+	withField  bool
+	tagPxNames []string
+	tagNames   []string
+
+	// 为true时key不存在也作为错误，否则保持field不变
+	strict bool
+	errs   Errors
+}
+
+func structValue(result interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Ptr {
+		return v, errors.New("result must be ptr")
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return v, errors.New("result must be struct ptr")
+	}
+	return v, nil
+}
+
+// 每个tag名称的初始前缀
+func (f *filler) prefixes(base string) []string {
+	ret := make([]string, len(f.tagPxNames))
+	for i := range ret {
+		ret[i] = base
+	}
+	return ret
+}
+
+// 填充struct，没有错误时调用Validator
+// param: path field路径
+// param: key struct对应的key
+// param: v struct
+func (f *filler) fillObject(path, key string, v reflect.Value) {
+	n := len(f.errs)
+	f.fillStruct(path, key, f.prefixes(key), v)
+	if len(f.errs) == n {
+		f.validateStruct(path, key, v)
+	}
+}
+
+// param: path 当前层级的field路径
+// param: base 当前层级的key
+// param: prefix 每个tag名称的前缀
+// param: v struct
+func (f *filler) fillStruct(path, base string, prefix []string, v reflect.Value) {
+	t := v.Type()
+	prefix = append([]string(nil), prefix...)
+	for i := 0; i < v.NumField(); i++ {
+		field := t.Field(i)
+		for tagIndex := range f.tagPxNames {
+			tagValue := field.Tag.Get(f.tagPxNames[tagIndex])
+			if tagValue != "" {
+				prefix[tagIndex] = joinPath(base, tagValue)
+				continue
+			}
+			tagValue = field.Tag.Get(f.tagNames[tagIndex])
+			if tagValue != "" {
+				if tagValue == "-" {
+					break
+				}
+			} else if tagIndex < len(f.tagPxNames)-1 {
+				continue
+			} else if field.Anonymous && f.fillEmbedded(fieldPath(path, field.Name), base, prefix, v.Field(i)) {
+				break
+			} else if f.withField {
+				tagValue = field.Name
+			}
+
+			if tagValue != "" {
+				fp := fieldPath(path, field.Name)
+				ft, err := parseTag(tagValue)
+				if err != nil {
+					f.addError(fp, joinPath(prefix[tagIndex], ft.name), ft, err)
+				} else if ft.inline {
+					if !f.fillInline(fp, base, prefix, v.Field(i)) {
+						f.addError(fp, base, ft, fmt.Errorf("%w: inline requires struct but get %v", ErrInvalidTag, field.Type))
+					}
+				} else {
+					if ft.name == "" && f.withField {
+						ft.name = field.Name
+					}
+					f.fillValue(fp, joinPath(prefix[tagIndex], ft.name), ft, v.Field(i))
+				}
+				break
+			}
+		}
+	}
+}
+
+// 未设置tag的匿名struct（或指针），field使用当前层级的前缀
+// return: 是否为匿名struct
+func (f *filler) fillEmbedded(path, base string, prefix []string, fv reflect.Value) bool {
+	t := fv.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !f.isNested(t) && !(f.withField && isPlainStruct(t)) {
+		return false
+	}
+	return f.fillInline(path, base, prefix, fv)
+}
+
+// struct（或指针）的field使用当前层级的前缀
+// return: 是否为struct
+func (f *filler) fillInline(path, base string, prefix []string, fv reflect.Value) bool {
+	t := fv.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			if !fv.CanSet() {
+				return true
+			}
+			fv.Set(reflect.New(t))
+		}
+		fv = fv.Elem()
+	}
+	f.fillStruct(path, base, prefix, fv)
+	return true
+}
+
+// 填充field，没有错误时使用tag中的规则校验
+func (f *filler) fillValue(path, key string, ft fieldTag, fv reflect.Value) {
+	n := len(f.errs)
+	f.fillField(path, key, ft, fv)
+	if len(f.errs) == n {
+		f.validateField(path, key, ft, fv)
+	}
+}
+
+// 取值顺序：属性值、env指定的环境变量、default，均不存在时仅required或strict时返回错误
+func (f *filler) fillField(path, key string, ft fieldTag, fv reflect.Value) {
+	present := f.present(key, ft)
+	if !present {
+		if text, ok := ft.fallback(f.prop); ok {
+			c := reflect.New(fv.Type()).Elem()
+			f.setField(path, key, ft, fv, c, setText(key, text, ft.split, getDecodeHooks(f.prop), c))
+			return
+		}
+		if ft.required {
+			f.addError(path, key, ft, keyNotFound(key))
+			return
+		}
+	}
+	if ft.split == "" && f.fillNested(path, key, fv) {
+		return
+	}
+	c := reflect.New(fv.Type()).Elem()
+	var err error
+	switch {
+	case !present:
+		err = keyNotFound(key)
+	case ft.split != "":
+		err = getSplitValue(f.prop, key, ft.split, c.Addr().Interface())
+	default:
+		err = f.prop.GetValue(key, c.Addr().Interface())
+	}
+	f.setField(path, key, ft, fv, c, err)
+}
+
+// 属性是否存在，omitempty时空值视为不存在
+func (f *filler) present(key string, ft fieldTag) bool {
+	if !f.prop.Has(key) {
+		return false
+	}
+	if !ft.omitempty {
+		return true
+	}
+	var v interface{}
+	if err := f.prop.GetValue(key, &v); err != nil {
+		return true
+	}
+	return !isEmptyValue(v)
+}
+
+// 成功时设置field，失败时保持field不变并记录错误
+func (f *filler) setField(path, key string, ft fieldTag, fv reflect.Value, c reflect.Value, err error) {
+	if err != nil {
+		f.addError(path, key, ft, err)
+		return
+	}
+	if fv.CanSet() {
+		fv.Set(c)
+	}
+}
+
+// 记录错误，非strict时忽略key不存在
+func (f *filler) addError(path, key string, ft fieldTag, err error) {
+	if !f.strict && !ft.required && errors.Is(err, ErrKeyNotFound) {
+		return
+	}
+	if ft.secret {
+		err = redact(err)
+	}
+	// 错误由Fill返回，不再输出日志
+	f.errs.AddError(&FieldError{Field: path, Key: key, Err: err})
+}
+
+// 填充带有tag的struct、struct指针、[]Struct及map[string]Struct
+// return: 是否已处理
+func (f *filler) fillNested(path, key string, fv reflect.Value) bool {
+	t := fv.Type()
+	switch t.Kind() {
+	case reflect.Struct:
+		if !f.isNested(t) {
+			return false
+		}
+		f.fillObject(path, key, fv)
+		return true
+	case reflect.Ptr:
+		if !f.isNested(t.Elem()) {
+			return false
+		}
+		if !f.prop.Has(key) {
+			f.addError(path, key, fieldTag{}, keyNotFound(key))
+			return true
+		}
+		if fv.IsNil() {
+			if !fv.CanSet() {
+				return true
+			}
+			fv.Set(reflect.New(t.Elem()))
+		}
+		f.fillObject(path, key, fv.Elem())
+		return true
+	case reflect.Slice:
+		if !f.isNestedElem(t.Elem()) {
+			return false
+		}
+		var raw []interface{}
+		if err := f.prop.GetValue(key, &raw); err != nil {
+			f.addError(path, key, fieldTag{}, err)
+			return true
+		}
+		s := reflect.MakeSlice(t, len(raw), len(raw))
+		for i := range raw {
+			f.fillElem(indexKey(path, i), indexKey(key, i), s.Index(i))
+		}
+		f.setField(path, key, fieldTag{}, fv, s, nil)
+		return true
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || !f.isNestedElem(t.Elem()) {
+			return false
+		}
+		var raw map[string]interface{}
+		if err := f.prop.GetValue(key, &raw); err != nil {
+			f.addError(path, key, fieldTag{}, err)
+			return true
+		}
+		m := reflect.MakeMapWithSize(t, len(raw))
+		for k := range raw {
+			ev := reflect.New(t.Elem()).Elem()
+			f.fillElem(path+"["+strconv.Quote(k)+"]", joinKey(key, k), ev)
+			m.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+		}
+		f.setField(path, key, fieldTag{}, fv, m, nil)
+		return true
+	}
+	return false
+}
+
+// 填充slice、map的元素，元素为struct或struct指针
+func (f *filler) fillElem(path, key string, ev reflect.Value) {
+	if ev.Kind() == reflect.Ptr {
+		ev.Set(reflect.New(ev.Type().Elem()))
+		ev = ev.Elem()
+	}
+	f.fillObject(path, key, ev)
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func (f *filler) isNestedElem(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return f.isNested(t)
+}
+
+// struct是否需要按field填充：不能从字符串转换、未实现Unmarshaler且包含tag
+func (f *filler) isNested(t reflect.Type) bool {
+	return isPlainStruct(t) && f.hasTags(t, map[reflect.Type]bool{})
+}
+
+// 不能从字符串转换且未实现Unmarshaler、FigUnmarshaler的struct
+func isPlainStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !hasStringDecoder(t) && !hasUnmarshaler(t) && !isFigUnmarshaler(t)
+}
+
+// struct或其匿名struct的field是否包含tag
+func (f *filler) hasTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		for j := range f.tagNames {
+			if field.Tag.Get(f.tagNames[j]) != "" || field.Tag.Get(f.tagPxNames[j]) != "" {
+				return true
+			}
+		}
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && f.hasTags(ft, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tag选项无效，使用errors.Is判断
+var ErrInvalidTag = errors.New("invalid tag option")
+
+// secret属性在错误信息中的显示
+const secretMask = "******"
+
+// fig tag：name[,option...]，选项的值中可以使用\,表示逗号
+// 支持的选项：default=value、required、env=VAR、split[=sep]、inline、omitempty、secret
+// 及校验规则：min=n、max=n、oneof=a b c、regex=expr、nonempty、url
+type fieldTag struct {
+	name string
+	// 属性不存在时使用的默认值
+	defaultValue string
+	hasDefault   bool
+	// 属性不存在时返回错误（Fill也返回）
+	required bool
+	// 属性不存在时使用的环境变量，优先于default
+	env string
+	// 属性值为字符串时拆分为slice或map使用的分隔符，为空时不拆分
+	split string
+	// struct的field使用当前层级的前缀
+	inline bool
+	// 属性值为空（null、""、空列表、空map）时视为不存在
+	omitempty bool
+	// 错误信息中不显示属性值
+	secret bool
+	// 校验规则
+	rules []rule
+}
+
+type parsedTag struct {
+	tag fieldTag
+	err error
+}
+
+// tag -> parsedTag，避免每次Fill重复解析及编译正则表达式
+var tagCache sync.Map
+
+func parseTag(tag string) (fieldTag, error) {
+	if v, ok := tagCache.Load(tag); ok {
+		p := v.(parsedTag)
+		return p.tag, p.err
+	}
+	ret, err := parseTagOptions(tag)
+	tagCache.Store(tag, parsedTag{ret, err})
+	return ret, err
+}
+
+func parseTagOptions(tag string) (fieldTag, error) {
+	items := splitTag(tag)
+	ret := fieldTag{name: items[0]}
+	for i := 1; i < len(items); i++ {
+		item := items[i]
+		name, value := item, ""
+		hasValue := false
+		if index := strings.Index(item, "="); index != -1 {
+			name, value, hasValue = item[:index], item[index+1:], true
+		}
+		switch name {
+		case "":
+			if hasValue {
+				return ret, fmt.Errorf("%w: %q", ErrInvalidTag, item)
+			}
+		case "default":
+			if !hasValue {
+				return ret, fmt.Errorf("%w: default requires value", ErrInvalidTag)
+			}
+			ret.defaultValue, ret.hasDefault = value, true
+		case "env":
+			if value == "" {
+				return ret, fmt.Errorf("%w: env requires variable name", ErrInvalidTag)
+			}
+			ret.env = value
+		case "split":
+			if value == "" {
+				ret.split = DefaultSeparator
+				// split=,中的逗号被当作选项分隔符
+				if hasValue && i+1 < len(items) && items[i+1] == "" {
+					i++
+				}
+			} else {
+				ret.split = value
+			}
+		case "min", "max", "oneof", "regex":
+			if !hasValue {
+				return ret, fmt.Errorf("%w: %s requires value", ErrInvalidTag, name)
+			}
+			r, err := newRule(name, value)
+			if err != nil {
+				return ret, err
+			}
+			ret.rules = append(ret.rules, r)
+		case "nonempty", "url":
+			if hasValue {
+				return ret, fmt.Errorf("%w: %s does not accept value", ErrInvalidTag, name)
+			}
+			r, _ := newRule(name, "")
+			ret.rules = append(ret.rules, r)
+		case "required", "inline", "omitempty", "secret":
+			if hasValue {
+				return ret, fmt.Errorf("%w: %s does not accept value", ErrInvalidTag, name)
+			}
+			switch name {
+			case "required":
+				ret.required = true
+			case "inline":
+				ret.inline = true
+			case "omitempty":
+				ret.omitempty = true
+			case "secret":
+				ret.secret = true
+			}
+		default:
+			return ret, fmt.Errorf("%w: unknown option %q", ErrInvalidTag, name)
+		}
+	}
+	return ret, nil
+}
+
+// 按逗号拆分tag，\,表示逗号，\\表示反斜杠，其他反斜杠保持不变
+func splitTag(tag string) []string {
+	var ret []string
+	buf := strings.Builder{}
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case c == '\\' && i+1 < len(tag) && (tag[i+1] == ',' || tag[i+1] == '\\'):
+			i++
+			buf.WriteByte(tag[i])
+		case c == ',':
+			ret = append(ret, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return append(ret, buf.String())
+}
+
+// 属性不存在时的取值：env指定的环境变量（包括props加载的.env文件）、default
+func (ft fieldTag) fallback(props Properties) (string, bool) {
+	if ft.env != "" {
+		if v, ok := lookupEnv(props, ft.env); ok {
+			return v, true
+		}
+	}
+	return ft.defaultValue, ft.hasDefault
+}
+
+// 将字符串转换为dst的类型，split不为空时拆分为slice或map
+func setText(key, text, split string, hooks []decodeHook, dst reflect.Value) error {
+	if split != "" {
+		return splitDecode(key, text, split, hooks, dst)
+	}
+	return setString(key, text, hooks, dst)
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch o := v.(type) {
+	case nil:
+		return true
+	case string:
+		return o == ""
+	}
+	if l, ok := listValue(v); ok {
+		return len(l) == 0
+	}
+	if m, ok := mapValue(v); ok {
+		return len(m) == 0
+	}
+	return false
+}
+
+// 去掉错误中的属性值
+func redact(err error) error {
+	var de *DecodeError
+	if errors.As(err, &de) {
+		return &DecodeError{Key: de.Key, Type: de.Type, Raw: secretMask}
+	}
+	return err
+}
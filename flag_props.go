@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const (
+	// 命令行中使用--set key=value设置属性
+	SetFlagName = "set"
+)
+
+// 可重复设置的key=value命令行参数，用于flag.FlagSet：
+//
+//	var kv fig.KeyValueFlag
+//	fs.Var(&kv, fig.SetFlagName, "set property key=value")
+type KeyValueFlag []string
+
+func (f *KeyValueFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *KeyValueFlag) Set(v string) error {
+	if strings.IndexByte(v, '=') <= 0 {
+		return fmt.Errorf("expect key=value but get: %s", v)
+	}
+	*f = append(*f, v)
+	return nil
+}
+
+// 从命令行参数创建属性，可通过MergeProperties叠加在配置文件之上：
+// * --A.B.C=value、-A.B.C=value 或 --A.B.C value（下一个参数不以'-'开头或为数字时作为值）
+// * --A.B.C 后面没有值时等同于 --A.B.C=true
+// * --set A.B.C=value 或 --set=A.B.C=value
+// * 其他非'-'开头的参数及"--"之后的参数被忽略
+// 参数值使用ValueLoader解析（如yaml中20为数字，true为布尔值，"1.10"为字符串1.10），解析为map、列表等非标量的值使用原始字符串
+// param: args 命令行参数，如os.Args[1:]
+func LoadArgs(args []string, opts ...Opt) (*DefaultProperties, error) {
+	var pairs [][2]string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name := strings.TrimPrefix(arg[1:], "-")
+		value := "true"
+		hasValue := false
+		if index := strings.IndexByte(name, '='); index != -1 {
+			name, value = name[:index], name[index+1:]
+			hasValue = true
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid argument: %s", arg)
+		}
+		if !hasValue && name != SetFlagName && i+1 < len(args) && isArgValue(args[i+1]) {
+			i++
+			value = args[i]
+		}
+		if name == SetFlagName {
+			if !hasValue {
+				i++
+				if i >= len(args) {
+					return nil, fmt.Errorf("argument %s needs a value", arg)
+				}
+				value = args[i]
+			}
+			kv := strings.SplitN(value, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("expect key=value but get: %s", value)
+			}
+			name, value = kv[0], kv[1]
+		}
+		pairs = append(pairs, [2]string{name, value})
+	}
+	return newArgsProperties(pairs, opts...)
+}
+
+// 参数是否可以作为前一个参数的值，"-1"等负数可以作为值
+func isArgValue(arg string) bool {
+	return arg != "--" && (arg == "" || arg[0] != '-' || isJsonNumber(arg))
+}
+
+// 从flag.FlagSet中显式设置的参数创建属性，参数名作为key，类型为*KeyValueFlag的参数展开为key=value
+// param: fs 已调用Parse的FlagSet
+func LoadFlagSet(fs *flag.FlagSet, opts ...Opt) (*DefaultProperties, error) {
+	var pairs [][2]string
+	fs.Visit(func(f *flag.Flag) {
+		if kv, ok := f.Value.(*KeyValueFlag); ok {
+			for _, v := range *kv {
+				pair := strings.SplitN(v, "=", 2)
+				pairs = append(pairs, [2]string{pair[0], pair[1]})
+			}
+			return
+		}
+		pairs = append(pairs, [2]string{f.Name, f.Value.String()})
+	})
+	return newArgsProperties(pairs, opts...)
+}
+
+// 解析参数值，只保留数字、布尔值及字符串，其他值（如"hello: world"解析得到的map）使用原始字符串
+func argValue(loader ValueLoader, s string) interface{} {
+	var o interface{}
+	if err := loader.Deserialize(s, &o); err != nil {
+		return s
+	}
+	switch o.(type) {
+	case bool, string:
+		return o
+	}
+	if _, ok := number(o); ok {
+		if isJsonNumber(s) {
+			// 保留数字的原始文本，避免大整数丢失精度
+			return json.Number(s)
+		}
+		return o
+	}
+	return s
+}
+
+func newArgsProperties(pairs [][2]string, opts ...Opt) (*DefaultProperties, error) {
+	ret := New(opts...)
+	if ret == nil {
+		return nil, fmt.Errorf("create properties failed")
+	}
+
+	v := Value{}
+	for _, pair := range pairs {
+		o := argValue(ret.loader, pair[1])
+		path, err := parseMapKey(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := setValue(v, path, o); err != nil {
+			return nil, err
+		}
+	}
+	ret.publish(&v, newProvenance(&v, nil, Source{Name: sourceArgs}))
+	return ret, nil
+}
@@ -3,7 +3,9 @@ module github.com/xfali/fig
 go 1.12
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/ghodss/yaml v1.0.0
-	github.com/xfali/reflection v0.0.0-20220705135531-464ba3201671 // indirect
+	github.com/xfali/reflection v0.0.0-20220705135531-464ba3201671
 	gopkg.in/yaml.v2 v2.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,190 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 类型实现该接口时（通常为指针接收者），GetValue及Fill使用UnmarshalFig转换属性值，优先于ValueLoader
+type FigUnmarshaler interface {
+	// param: v 属性值的副本，可能为nil、string、bool、int64、uint64、float64、[]interface{}或map[string]interface{}
+	UnmarshalFig(v interface{}) error
+}
+
+// 将属性值转换为目标类型
+// param: v 属性值的副本，类型同FigUnmarshaler
+// return: 可以赋值给目标类型的值
+type DecodeHookFunc func(v interface{}) (interface{}, error)
+
+type decodeHook struct {
+	from reflect.Type
+	to   reflect.Type
+	fn   DecodeHookFunc
+}
+
+var figUnmarshalerType = reflect.TypeOf((*FigUnmarshaler)(nil)).Elem()
+
+// 注册类型转换函数，GetValue及Fill转换为to类型时优先使用，先注册的优先
+// param: from 属性值的类型，如reflect.TypeOf("")，与传入fn的值（类型同FigUnmarshaler）比较，为nil时匹配所有类型；
+// 数字类型（如reflect.TypeOf(float64(0))）匹配所有可以精确转换为该类型的数字，传入fn前转换为该类型
+// param: to 目标类型，如reflect.TypeOf(&regexp.Regexp{})
+// param: fn 转换函数
+func DecodeHook(from reflect.Type, to reflect.Type, fn DecodeHookFunc) Opt {
+	return func(ctx *DefaultProperties) error {
+		ctx.DecodeHook(from, to, fn)
+		return nil
+	}
+}
+
+// 应在读取属性前注册
+func (ctx *DefaultProperties) DecodeHook(from reflect.Type, to reflect.Type, fn DecodeHookFunc) {
+	ctx.hooks = append(ctx.hooks, decodeHook{
+		from: from,
+		to:   to,
+		fn:   fn,
+	})
+}
+
+// 是否注册了转换为t（或t指向的类型）的函数
+func (ctx *DefaultProperties) hasDecodeHook(t reflect.Type) bool {
+	for _, h := range ctx.hooks {
+		if h.to == t || (t != nil && t.Kind() == reflect.Ptr && h.to == t.Elem()) {
+			return true
+		}
+	}
+	return false
+}
+
+// 提供注册的类型转换函数，用于转换default、split等字符串
+type decodeHookGetter interface {
+	getDecodeHooks() []decodeHook
+}
+
+// return: props注册的类型转换函数，未实现decodeHookGetter时返回nil
+func getDecodeHooks(props Properties) []decodeHook {
+	if g, ok := props.(decodeHookGetter); ok {
+		return g.getDecodeHooks()
+	}
+	return nil
+}
+
+func (ctx *DefaultProperties) getDecodeHooks() []decodeHook {
+	return ctx.hooks
+}
+
+func (p *subProperties) getDecodeHooks() []decodeHook {
+	return getDecodeHooks(p.parent)
+}
+
+func (p *weakProperties) getDecodeHooks() []decodeHook {
+	return getDecodeHooks(p.Properties)
+}
+
+// return: 按优先级依次返回各属性注册的类型转换函数
+func (p *mergedProperties) getDecodeHooks() []decodeHook {
+	var ret []decodeHook
+	for _, prop := range p.props {
+		ret = append(ret, getDecodeHooks(prop)...)
+	}
+	return ret
+}
+
+// 支持DecodeHook的ValueDecoder，内部的loader均实现该接口
+type hookDecoder interface {
+	decodeHooks(o interface{}, result interface{}, hooks []decodeHook) error
+}
+
+// 转换为DecodeHook的输入
+// param: from 注册的输入类型，为nil时不检查
+// param: o normalizeValue转换后的属性值
+// return: 数字转换为from的数字类型，无法精确转换或类型不一致时返回false
+func hookInput(from reflect.Type, o interface{}) (interface{}, bool) {
+	if from == nil || reflect.TypeOf(o) == from {
+		return o, true
+	}
+	if _, ok := exactNumber(o); !ok || !isNumberKind(from.Kind()) {
+		return nil, false
+	}
+	rv := reflect.New(from).Elem()
+	d := valueDecoder{}
+	if err := d.decodeValue("", o, rv); err != nil {
+		return nil, false
+	}
+	return rv.Interface(), true
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// 是否使用FigUnmarshaler转换
+func isFigUnmarshaler(t reflect.Type) bool {
+	if t == nil || t.Kind() == reflect.Interface {
+		return false
+	}
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PtrTo(t)
+	}
+	return t.Implements(figUnmarshalerType)
+}
+
+// 使用DecodeHook或FigUnmarshaler转换
+// return: 是否已处理
+func (d *valueDecoder) decodeCustom(key string, v interface{}, dst reflect.Value) (bool, error) {
+	t := dst.Type()
+	var o interface{}
+	normalized := false
+	for _, h := range d.hooks {
+		if h.to != t {
+			continue
+		}
+		// from与转换后的格式比较，与传入函数的值一致
+		if !normalized {
+			n, err := normalizeValue(v)
+			if err != nil {
+				return true, err
+			}
+			o, normalized = n, true
+		}
+		in, ok := hookInput(h.from, o)
+		if !ok {
+			continue
+		}
+		ret, err := h.fn(in)
+		if err != nil {
+			return true, decodeError(key, v, t, err)
+		}
+		rv := reflect.ValueOf(ret)
+		if !rv.IsValid() {
+			dst.Set(reflect.Zero(t))
+			return true, nil
+		}
+		if !rv.Type().AssignableTo(t) {
+			return true, decodeError(key, v, t, fmt.Errorf("decode hook returns %v", rv.Type()))
+		}
+		dst.Set(rv)
+		return true, nil
+	}
+	if t.Kind() != reflect.Ptr && dst.CanAddr() && dst.Addr().Type().Implements(figUnmarshalerType) {
+		o, err := normalizeValue(v)
+		if err != nil {
+			return true, err
+		}
+		if err := dst.Addr().Interface().(FigUnmarshaler).UnmarshalFig(o); err != nil {
+			return true, decodeError(key, v, t, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
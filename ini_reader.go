@@ -0,0 +1,71 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 读取ini文件，[section]下的key=value转换为section.key，section名称中的'.'表示嵌套，所有的值均为string
+// 以'#'或';'开头的行为注释，转义及续行规则与.properties一致，值两端的双引号会被去除
+type IniReader struct{}
+
+func NewIniReader() *IniReader {
+	return &IniReader{}
+}
+
+func (v *IniReader) Read(r io.Reader) (*Value, error) {
+	ret, _, err := v.ReadWithPosition(r)
+	return ret, err
+}
+
+func (v *IniReader) ReadWithPosition(r io.Reader) (*Value, map[string]Position, error) {
+	lines, err := readLogicalLines(r, "#;")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret := Value{}
+	pos := map[string]Position{}
+	var section []string
+	for _, l := range lines {
+		text := strings.TrimRight(l.text, " \t\f")
+		if text[0] == '[' {
+			if text[len(text)-1] != ']' {
+				return nil, nil, fmt.Errorf("line %d: malformed section: %s", l.line, text)
+			}
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			if name == "" {
+				return nil, nil, fmt.Errorf("line %d: empty section name", l.line)
+			}
+			section = strings.Split(name, ".")
+			for i := range section {
+				section[i] = strings.TrimSpace(section[i])
+			}
+			if _, err := setPropertyValue(ret, pos, section, map[string]interface{}{}); err != nil {
+				return nil, nil, fmt.Errorf("line %d: %s", l.line, err.Error())
+			}
+			pos[pathKey(section)] = Position{Line: l.line, Column: l.column}
+			continue
+		}
+
+		key, value, err := parseKeyValue(text)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", l.line, err.Error())
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		path, err := setPropertyValue(ret, pos, append(append([]string{}, section...), strings.Split(key, ".")...), value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", l.line, err.Error())
+		}
+		pos[pathKey(path)] = Position{Line: l.line, Column: l.column}
+	}
+	return &ret, pos, nil
+}
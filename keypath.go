@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// key路径中的一段，Index >= 0时为列表下标，否则为map的key
+type keySegment struct {
+	Key   string
+	Index int
+}
+
+// 解析key路径：
+// * A.B.C：使用'.'分隔map的key
+// * Servers[0].Host：使用[n]访问列表元素
+// * Headers["X-Trace-Id"]、Headers['X-Trace-Id']：引号内为完整的key，可以包含任意字符，使用'\'转义引号
+// * a\.b：使用'\'转义'.'、'['、']'及'\'
+// 空字符串表示根节点
+func parseKey(key string) ([]keySegment, error) {
+	var ret []keySegment
+	i := 0
+	// 上一段是否刚结束（下一段必须以'.'或'['开始）
+	closed := false
+	for i < len(key) {
+		c := key[i]
+		switch {
+		case c == '[':
+			seg, n, err := parseBracket(key, i)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, seg)
+			i = n
+			closed = true
+		case c == '.':
+			if !closed {
+				return nil, parseError(key, i, "empty segment")
+			}
+			i++
+			closed = false
+			if i >= len(key) {
+				return nil, parseError(key, i, "empty segment")
+			}
+		default:
+			if closed {
+				return nil, parseError(key, i, "expect '.' or '['")
+			}
+			start := i
+			escaped := false
+			buf := strings.Builder{}
+			for i < len(key) && key[i] != '.' && key[i] != '[' {
+				if key[i] == '\\' {
+					if !escaped {
+						escaped = true
+						buf.WriteString(key[start:i])
+					}
+					i++
+					if i >= len(key) {
+						return nil, parseError(key, i, "unexpected end after '\\'")
+					}
+				} else if key[i] == ']' {
+					return nil, parseError(key, i, "unexpected ']'")
+				}
+				if escaped {
+					buf.WriteByte(key[i])
+				}
+				i++
+			}
+			if escaped {
+				ret = append(ret, keySegment{Key: buf.String(), Index: -1})
+			} else {
+				ret = append(ret, keySegment{Key: key[start:i], Index: -1})
+			}
+			closed = true
+		}
+	}
+	return ret, nil
+}
+
+// 解析key[start]开始的[n]或["key"]
+// return: 解析结果及']'之后的位置
+func parseBracket(key string, start int) (keySegment, int, error) {
+	i := start + 1
+	if i >= len(key) {
+		return keySegment{}, 0, parseError(key, start, "unclosed '['")
+	}
+	if q := key[i]; q == '"' || q == '\'' {
+		buf := strings.Builder{}
+		for i++; i < len(key) && key[i] != q; i++ {
+			if key[i] == '\\' {
+				i++
+				if i >= len(key) {
+					break
+				}
+			}
+			buf.WriteByte(key[i])
+		}
+		if i+1 >= len(key) || key[i+1] != ']' {
+			return keySegment{}, 0, parseError(key, start, "unclosed '['")
+		}
+		return keySegment{Key: buf.String(), Index: -1}, i + 2, nil
+	}
+
+	end := strings.IndexByte(key[i:], ']')
+	if end == -1 {
+		return keySegment{}, 0, parseError(key, start, "unclosed '['")
+	}
+	index, err := strconv.Atoi(key[i : i+end])
+	if err != nil || index < 0 {
+		return keySegment{}, 0, parseError(key, start, "invalid index %q", key[i:i+end])
+	}
+	return keySegment{Index: index}, i + end + 1, nil
+}
+
+// 将key路径格式化为字符串，包含特殊字符的key使用["key"]格式
+func formatKey(segs []keySegment) string {
+	buf := strings.Builder{}
+	for _, seg := range segs {
+		if seg.Index >= 0 {
+			buf.WriteString("[")
+			buf.WriteString(strconv.Itoa(seg.Index))
+			buf.WriteString("]")
+			continue
+		}
+		if seg.Key == "" || strings.ContainsAny(seg.Key, ".[]\\\"'") {
+			buf.WriteString(`["`)
+			buf.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(seg.Key))
+			buf.WriteString(`"]`)
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(".")
+		}
+		buf.WriteString(seg.Key)
+	}
+	return buf.String()
+}
+
+// 根据key路径查找值，列表下标也可以用于访问key为数字的map
+func lookupPath(v interface{}, segs []keySegment) (interface{}, bool) {
+	for _, seg := range segs {
+		switch o := v.(type) {
+		case map[string]interface{}:
+			k := seg.Key
+			if seg.Index >= 0 {
+				k = strconv.Itoa(seg.Index)
+			}
+			var ok bool
+			if v, ok = o[k]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			if seg.Index < 0 || seg.Index >= len(o) {
+				return nil, false
+			}
+			v = o[seg.Index]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// 拼接前缀与key
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	if key[0] == '[' {
+		return prefix + key
+	}
+	return prefix + "." + key
+}
+
+// 将key转换为统一格式（同Explain），无法解析时返回原key
+func canonicalKey(key string) string {
+	segs, err := parseKey(key)
+	if err != nil {
+		return key
+	}
+	return formatKey(segs)
+}
+
+// 将map的key路径转换为字符串
+func pathKey(path []string) string {
+	segs := make([]keySegment, len(path))
+	for i := range path {
+		segs[i] = keySegment{Key: path[i], Index: -1}
+	}
+	return formatKey(segs)
+}
+
+// 解析只包含map key的路径，用于创建属性
+func parseMapKey(key string) ([]string, error) {
+	segs, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("key is empty")
+	}
+	ret := make([]string, len(segs))
+	for i, seg := range segs {
+		if seg.Index >= 0 {
+			return nil, fmt.Errorf("key: %s list index is not supported", key)
+		}
+		ret[i] = seg.Key
+	}
+	return ret, nil
+}
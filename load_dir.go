@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// LoadDir加载的配置文件名称（不含扩展名）
+	ApplicationName = "application"
+	// 未指定profile时从该环境变量读取，多个profile使用','分隔
+	ProfilesEnvName = "FIG_PROFILES_ACTIVE"
+)
+
+type dirFileType struct {
+	ext    string
+	reader func() ValueReader
+	// 值均为字符串，加载后合并的属性使用弱类型转换
+	weak bool
+}
+
+// 同名的多个文件按该顺序加载
+var dirFileTypes = []dirFileType{
+	{ext: ".json", reader: func() ValueReader { return NewJsonReader() }},
+	{ext: ".yaml", reader: func() ValueReader { return NewYamlReader() }},
+	{ext: ".yml", reader: func() ValueReader { return NewYamlReader() }},
+	{ext: ".toml", reader: func() ValueReader { return NewTomlReader() }},
+	{ext: ".properties", reader: func() ValueReader { return NewPropertiesReader() }, weak: true},
+}
+
+// 加载目录中的application配置，并依次深度合并各profile的配置：
+// application.yaml -> application-dev.yaml -> application-prod.yaml ...
+// 支持.json、.yaml、.yml、.toml、.properties文件，不存在的profile配置被忽略，
+// 加载了.properties文件时返回的属性使用弱类型转换
+// param: dir 配置目录
+// param: profiles 激活的profile，后面的优先；为空时从环境变量FIG_PROFILES_ACTIVE读取
+// return: 合并后的属性，目录中不存在任何配置文件时返回错误
+func LoadDir(dir string, profiles ...string) (*DefaultProperties, error) {
+	if len(profiles) == 0 {
+		profiles = activeProfiles()
+	}
+
+	names := []string{ApplicationName}
+	for _, p := range profiles {
+		names = append(names, ApplicationName+"-"+p)
+	}
+
+	var ret *DefaultProperties
+	for _, name := range names {
+		for _, t := range dirFileTypes {
+			filename := filepath.Join(dir, name+t.ext)
+			p, err := loadDirFile(filename, t.reader())
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("load %s failed: %s", filename, err.Error())
+			}
+			if t.weak {
+				p.SetWeaklyTyped(true)
+			}
+			if ret == nil {
+				ret = p
+			} else {
+				ret.weaklyTyped = ret.weaklyTyped || p.weaklyTyped
+				// ret尚未返回给调用方，可以直接修改其快照
+				dst, src := ret.load(), p.load()
+				m := &valueMerger{
+					strategy: ListReplace,
+					dstProv:  dst.provenance,
+					srcProv:  src.provenance,
+				}
+				m.mergeValue(*dst.value, *src.value, "")
+			}
+		}
+	}
+	if ret == nil {
+		return nil, fmt.Errorf("no %s config found in %s", ApplicationName, dir)
+	}
+	return ret, nil
+}
+
+func activeProfiles() []string {
+	var ret []string
+	for _, p := range strings.Split(os.Getenv(ProfilesEnvName), ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+func loadDirFile(filename string, reader ValueReader) (*DefaultProperties, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	prop := New()
+	prop.SetValueReader(reader)
+	prop.sourceFile = filename
+	err = prop.ReadValue(f)
+	if err != nil {
+		return nil, err
+	}
+	return prop, nil
+}
@@ -26,6 +26,14 @@ type ValueLoader interface {
 	Deserializer
 }
 
+// 可选接口，ValueLoader实现该接口时GetValue直接将Value中的值转换为result，不再序列化再反序列化
+type ValueDecoder interface {
+	// param: v Value中的值
+	// param: result 填充对象指针
+	// return: 不支持的类型返回ErrDecodeUnsupported，此时使用Serialize/Deserialize处理
+	Decode(v interface{}, result interface{}) error
+}
+
 type Properties interface {
 	// 配置ValueReader
 	SetValueReader(r ValueReader)
@@ -45,4 +53,23 @@ type Properties interface {
 	// param: result: 填充对象指针
 	// return: 正常返回nil,否则返回错误
 	GetValue(key string, result interface{}) error
+
+	// param: key属性名称，为空时返回全部
+	// return: key及其子节点的属性值来源
+	Explain(key string) []Provenance
+
+	// param: key属性名称
+	// return: key是否存在（值为null时也返回true）
+	Has(key string) bool
+
+	// param: prefix 节点名称，为空时为根节点
+	// return: 节点的直接子节点的key（完整路径，列表元素为prefix[n]），已排序，节点不存在或不为map、列表时返回空
+	Keys(prefix string) []string
+
+	// param: prefix 节点名称
+	// return: 以prefix为根节点的属性视图，随原属性变化，prefix不存在时返回nil
+	Sub(prefix string) Properties
+
+	// return: 全部属性值的副本
+	AllSettings() map[string]interface{}
 }
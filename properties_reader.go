@@ -0,0 +1,276 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// .properties、ini文件中同一个key既有值又有子key时（如log.level=INFO及log.level.root=DEBUG），
+// 该key的值保存在此key下。Get及转换为非map、struct的类型时自动使用该值，如Get("log.level", "")返回INFO；
+// 该key为保留key，.properties、ini文件中不能直接使用
+const PropertiesLeafKey = "_value"
+
+// v为包含PropertiesLeafKey的map且t不是map、struct等复合类型时返回PropertiesLeafKey对应的值
+// param: t 目标类型，为nil时视为string
+func leafValue(v interface{}, t reflect.Type) (interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	leaf, ok := m[PropertiesLeafKey]
+	if !ok {
+		return nil, false
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && !hasStringDecoder(t) {
+		switch t.Kind() {
+		case reflect.Map, reflect.Struct, reflect.Interface, reflect.Slice, reflect.Array:
+			return nil, false
+		}
+	}
+	return leaf, true
+}
+
+// 读取java格式的.properties文件，a.b.c=value会转换为嵌套的Value，所有的值均为string
+// 注释、转义及续行规则与java.util.Properties一致
+type PropertiesReader struct{}
+
+func NewPropertiesReader() *PropertiesReader {
+	return &PropertiesReader{}
+}
+
+func (v *PropertiesReader) Read(r io.Reader) (*Value, error) {
+	ret, _, err := v.ReadWithPosition(r)
+	return ret, err
+}
+
+func (v *PropertiesReader) ReadWithPosition(r io.Reader) (*Value, map[string]Position, error) {
+	lines, err := readLogicalLines(r, "#!")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret := Value{}
+	pos := map[string]Position{}
+	for _, l := range lines {
+		key, value, err := parseKeyValue(l.text)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", l.line, err.Error())
+		}
+		path, err := setPropertyValue(ret, pos, strings.Split(key, "."), value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", l.line, err.Error())
+		}
+		pos[pathKey(path)] = Position{Line: l.line, Column: l.column}
+	}
+	return &ret, pos, nil
+}
+
+// 设置path对应的值，同一个key既有值又有子key时，值移动到PropertiesLeafKey下
+// param: pos 已读取的值的位置，值移动时同时更新
+// param: value 值，为map时表示section，已存在的map保持不变
+// return: 值实际的路径，path包含PropertiesLeafKey时返回错误
+func setPropertyValue(v map[string]interface{}, pos map[string]Position, path []string, value interface{}) ([]string, error) {
+	for _, k := range path {
+		if k == PropertiesLeafKey {
+			return nil, fmt.Errorf("%s is reserved: %s", PropertiesLeafKey, strings.Join(path, "."))
+		}
+	}
+	for i, k := range path[:len(path)-1] {
+		switch o := v[k].(type) {
+		case map[string]interface{}:
+			v = o
+		case nil:
+			m := map[string]interface{}{}
+			v[k] = m
+			v = m
+		default:
+			v = moveLeafValue(v, pos, path[:i+1])
+		}
+	}
+	k := path[len(path)-1]
+	_, isMap := value.(map[string]interface{})
+	switch o := v[k].(type) {
+	case map[string]interface{}:
+		if isMap {
+			return path, nil
+		}
+		o[PropertiesLeafKey] = value
+		return append(append([]string{}, path...), PropertiesLeafKey), nil
+	case nil:
+	default:
+		if isMap {
+			moveLeafValue(v, pos, path)
+			return path, nil
+		}
+	}
+	v[k] = value
+	return path, nil
+}
+
+// 将path最后一个key的值移动到PropertiesLeafKey下
+// param: v path最后一个key所在的map
+// return: 替换原值的map
+func moveLeafValue(v map[string]interface{}, pos map[string]Position, path []string) map[string]interface{} {
+	k := path[len(path)-1]
+	m := map[string]interface{}{PropertiesLeafKey: v[k]}
+	v[k] = m
+	old := pathKey(path)
+	if p, ok := pos[old]; ok {
+		delete(pos, old)
+		pos[pathKey(append(append([]string{}, path...), PropertiesLeafKey))] = p
+	}
+	return m
+}
+
+type logicalLine struct {
+	// 起始行号及列号，从1开始
+	line   int
+	column int
+	text   string
+}
+
+// 读取逻辑行：忽略空行及注释行，以奇数个'\'结尾的行与下一行合并（下一行的前导空白被忽略）
+func readLogicalLines(r io.Reader, commentChars string) ([]logicalLine, error) {
+	scanner := bufio.NewScanner(r)
+	var ret []logicalLine
+	buf := strings.Builder{}
+	start, column, lineNo := 0, 0, 0
+	continued := false
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimRight(scanner.Text(), "\r")
+		text := strings.TrimLeft(raw, " \t\f")
+		if !continued {
+			if text == "" || strings.IndexByte(commentChars, text[0]) != -1 {
+				continue
+			}
+			start = lineNo
+			column = len(raw) - len(text) + 1
+		}
+
+		slashes := 0
+		for i := len(text) - 1; i >= 0 && text[i] == '\\'; i-- {
+			slashes++
+		}
+		continued = slashes%2 == 1
+		if continued {
+			buf.WriteString(text[:len(text)-1])
+			continue
+		}
+		buf.WriteString(text)
+		ret = append(ret, logicalLine{line: start, column: column, text: buf.String()})
+		buf.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if continued {
+		ret = append(ret, logicalLine{line: start, column: column, text: buf.String()})
+	}
+	return ret, nil
+}
+
+// key以第一个未转义的'='、':'或空白结束，之后的空白及一个'='或':'被忽略
+func parseKeyValue(text string) (string, string, error) {
+	i := 0
+	for ; i < len(text); i++ {
+		c := text[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+	}
+	if i > len(text) {
+		i = len(text)
+	}
+	key := text[:i]
+	rest := strings.TrimLeft(text[i:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(key)
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') == -1 {
+		return s, nil
+	}
+	buf := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(s) {
+			break
+		}
+		switch s[i] {
+		case 't':
+			buf.WriteByte('\t')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'u':
+			r, err := parseUnicodeEscape(s, i)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// 代理对（surrogate pair）由两个连续的\uxxxx组成
+			if utf16.IsSurrogate(r) && i+2 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				r2, err := parseUnicodeEscape(s, i+2)
+				if err != nil {
+					return "", err
+				}
+				r = utf16.DecodeRune(r, r2)
+				i += 6
+			}
+			buf.WriteRune(r)
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+	return buf.String(), nil
+}
+
+// 解析s[i]开始的uxxxx
+func parseUnicodeEscape(s string, i int) (rune, error) {
+	if i+4 >= len(s) {
+		return 0, fmt.Errorf("malformed \\uxxxx encoding: %s", s)
+	}
+	r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\uxxxx encoding: %s", s)
+	}
+	return rune(r), nil
+}
@@ -0,0 +1,239 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 属性值在文件中的位置，从1开始，0表示未知
+type Position struct {
+	Line   int
+	Column int
+}
+
+// 可以提供属性位置的ValueReader
+type PositionReader interface {
+	ValueReader
+
+	// return: 属性值及每个叶子节点（key格式同Explain）的位置
+	ReadWithPosition(r io.Reader) (*Value, map[string]Position, error)
+}
+
+// 属性值的来源
+type Source struct {
+	// 来源名称，如文件路径、"env APP_SERVERPORT"、"args"
+	Name string
+	// 文件路径，非文件来源为空
+	File string
+	Position
+}
+
+func (s Source) String() string {
+	if s.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d", s.Name, s.Line, s.Column)
+	}
+	return s.Name
+}
+
+// 叶子节点属性值的来源
+type Provenance struct {
+	// 叶子节点的key，如DataSources.default.MaxConn、Servers[0].Host
+	Key string
+	// 当前生效值的来源
+	Source Source
+	// 被覆盖的来源，按覆盖的先后顺序排列
+	Overridden []Source
+}
+
+func (p Provenance) String() string {
+	buf := strings.Builder{}
+	buf.WriteString(p.Key)
+	buf.WriteString(" from ")
+	buf.WriteString(p.Source.String())
+	for i := len(p.Overridden) - 1; i >= 0; i-- {
+		buf.WriteString(", overrides ")
+		buf.WriteString(p.Overridden[i].String())
+	}
+	return buf.String()
+}
+
+type provenanceMap map[string]*Provenance
+
+const (
+	sourceReader = "reader"
+	sourceArgs   = "args"
+	sourceSet    = "set"
+	sourceEnv    = "env "
+)
+
+// 配置属性的来源名称，用于Explain，从文件加载时默认为文件路径
+func SetSourceName(name string) Opt {
+	return func(ctx *DefaultProperties) error {
+		ctx.sourceName = name
+		return nil
+	}
+}
+
+// 查询属性值的来源
+// param: key 属性名称，为叶子节点时返回该节点的来源，否则返回所有子节点的来源，为空时返回全部
+// return: 按key排序的来源
+func (ctx *DefaultProperties) Explain(key string) []Provenance {
+	return ctx.load().provenance.explain(key)
+}
+
+func (ctx *DefaultProperties) source() Source {
+	name := ctx.sourceName
+	if name == "" {
+		name = ctx.sourceFile
+	}
+	if name == "" {
+		name = sourceReader
+	}
+	return Source{
+		Name: name,
+		File: ctx.sourceFile,
+	}
+}
+
+// 为v的每个叶子节点记录来源
+func newProvenance(v *Value, pos map[string]Position, source Source) provenanceMap {
+	ret := provenanceMap{}
+	if v != nil {
+		ret.add("", map[string]interface{}(*v), pos, source)
+	}
+	return ret
+}
+
+func (m provenanceMap) add(key string, v interface{}, pos map[string]Position, source Source) {
+	switch o := v.(type) {
+	case map[string]interface{}:
+		if len(o) > 0 {
+			for k, v := range o {
+				m.add(joinKey(key, k), v, pos, source)
+			}
+			return
+		}
+	case []interface{}:
+		if len(o) > 0 {
+			for i, v := range o {
+				m.add(indexKey(key, i), v, pos, source)
+			}
+			return
+		}
+	}
+	if key == "" {
+		return
+	}
+	s := source
+	if pos != nil {
+		s.Position = pos[key]
+	}
+	m.set(key, s)
+}
+
+// 设置key的来源，原有来源加入覆盖链
+func (m provenanceMap) set(key string, source Source) {
+	p := &Provenance{
+		Key:    key,
+		Source: source,
+	}
+	if old, ok := m[key]; ok {
+		p.Overridden = append(append([]Source{}, old.Overridden...), old.Source)
+	}
+	m[key] = p
+}
+
+// 使用source替换key及其子节点的来源，v为key的新值，与原有叶子节点key相同的保留覆盖链
+func (m provenanceMap) replace(key string, v interface{}, source Source) {
+	old := provenanceMap{}
+	old.take(m, key, key)
+	m.remove(key)
+	n := provenanceMap{}
+	n.add(key, v, nil, source)
+	for k, p := range n {
+		if o, ok := old[k]; ok {
+			p.Overridden = append(append([]Source{}, o.Overridden...), o.Source)
+		}
+		m[k] = p
+	}
+}
+
+// 删除key及其子节点的来源
+func (m provenanceMap) remove(key string) {
+	for k := range m {
+		if isSubKey(k, key) {
+			delete(m, k)
+		}
+	}
+}
+
+// 将src中from及其子节点的来源移动到to下
+func (m provenanceMap) take(src provenanceMap, from, to string) {
+	if src == nil {
+		return
+	}
+	for k, p := range src {
+		if !isSubKey(k, from) {
+			continue
+		}
+		newKey := to + k[len(from):]
+		n := &Provenance{
+			Key:        newKey,
+			Source:     p.Source,
+			Overridden: p.Overridden,
+		}
+		if old, ok := m[newKey]; ok {
+			n.Overridden = append(append(append([]Source{}, old.Overridden...), old.Source), p.Overridden...)
+		}
+		m[newKey] = n
+	}
+}
+
+func (m provenanceMap) copy() provenanceMap {
+	ret := make(provenanceMap, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
+
+func (m provenanceMap) explain(key string) []Provenance {
+	key = canonicalKey(key)
+	var ret []Provenance
+	for k, p := range m {
+		if key == "" || isSubKey(k, key) {
+			ret = append(ret, *p)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Key < ret[j].Key
+	})
+	return ret
+}
+
+// key是否为parent本身或其子节点
+func isSubKey(key, parent string) bool {
+	if parent == "" {
+		return true
+	}
+	if !strings.HasPrefix(key, parent) {
+		return false
+	}
+	if len(key) == len(parent) {
+		return true
+	}
+	c := key[len(parent)]
+	return c == '.' || c == '['
+}
+
+func indexKey(prefix string, i int) string {
+	return prefix + "[" + strconv.Itoa(i) + "]"
+}
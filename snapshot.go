@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"sync"
+)
+
+// 配置的不可变快照，Get、GetValue、Explain读取当前快照，无需加锁
+// ReadValue、Set等修改操作不修改已发布的快照，而是创建新的快照整体替换
+type snapshot struct {
+	value      *Value
+	provenance provenanceMap
+	// 发布时的环境变量（包括.env文件），用于tag的env选项
+	env map[string]string
+
+	// Get的结果，key -> string
+	cache sync.Map
+	// GetValue使用Serialize/Deserialize时的序列化结果，key -> string，与Get的格式不同，需分开保存
+	valueCache sync.Map
+}
+
+var emptySnapshot = &snapshot{}
+
+// 获得当前快照
+func (ctx *DefaultProperties) load() *snapshot {
+	if s, ok := ctx.current.Load().(*snapshot); ok {
+		return s
+	}
+	return emptySnapshot
+}
+
+// 发布新的快照，调用方需持有ctx.lock
+// param: v 新的属性值，发布后不能再修改
+// param: prov v对应的来源，发布后不能再修改
+func (ctx *DefaultProperties) publish(v *Value, prov provenanceMap) {
+	if prov == nil {
+		prov = provenanceMap{}
+	}
+	// 仅为兼容镜像到只读的Value字段，读取方应使用快照
+	ctx.Value = v
+	ctx.current.Store(&snapshot{
+		value:      v,
+		provenance: prov,
+		env:        ctx.Env,
+	})
+}
+
+// 按key路径直接查找Value，不依赖模板解析
+func (s *snapshot) lookup(key string) (interface{}, error) {
+	segs, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var root interface{}
+	if s.value != nil {
+		root = map[string]interface{}(*s.value)
+	}
+	v, ok := lookupPath(root, segs)
+	if !ok {
+		return nil, keyNotFound(key)
+	}
+	return v, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/xfali/reflection"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 默认分隔符
+const DefaultSeparator = ","
+
+// 拆分字符串并去除每一项的首尾空白，空字符串返回空列表
+func splitString(s, sep string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return []string{}
+	}
+	ret := strings.Split(s, sep)
+	for i := range ret {
+		ret[i] = strings.TrimSpace(ret[i])
+	}
+	return ret
+}
+
+// 将字符串转换为dst的类型
+// param: hooks 注册的类型转换函数
+func setString(key string, s string, hooks []decodeHook, dst reflect.Value) error {
+	d := valueDecoder{hooks: hooks}
+	if ok, err := d.decodeCustom(key, s, dst); ok {
+		return err
+	}
+	if dst.Kind() == reflect.Ptr {
+		e := reflect.New(dst.Type().Elem())
+		if err := setString(key, s, hooks, e.Elem()); err != nil {
+			return err
+		}
+		dst.Set(e)
+		return nil
+	}
+	if f, ok := stringDecoders[dst.Type()]; ok {
+		o, err := f(strings.TrimSpace(s))
+		if err != nil {
+			return decodeError(key, s, dst.Type(), err)
+		}
+		dst.Set(reflect.ValueOf(o))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+		return nil
+	case reflect.Interface:
+		if dst.NumMethod() == 0 {
+			dst.Set(reflect.ValueOf(s))
+			return nil
+		}
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return decodeError(key, s, dst.Type(), err)
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		n := strings.TrimSpace(s)
+		if !isJsonNumber(n) {
+			return decodeError(key, s, dst.Type(), errors.New("not a number"))
+		}
+		return d.decodeValue(key, json.Number(n), dst)
+	}
+	if ok := reflection.SetValue(dst, reflect.ValueOf(s)); !ok {
+		return decodeError(key, s, dst.Type(), nil)
+	}
+	return nil
+}
+
+// 将分隔的字符串转换为slice或map，map的每一项格式为k=v
+// param: key 属性key，用于错误信息
+// param: s 分隔的字符串，如"a,b,c"、"a=1,b=2"
+// param: sep 分隔符
+// param: hooks 注册的类型转换函数，用于转换每一项
+// param: dst slice或map
+func splitDecode(key string, s string, sep string, hooks []decodeHook, dst reflect.Value) error {
+	items := splitString(s, sep)
+	switch dst.Kind() {
+	case reflect.Slice:
+		ret := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i := range items {
+			if err := setString(indexKey(key, i), items[i], hooks, ret.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(ret)
+		return nil
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			break
+		}
+		ret := reflect.MakeMapWithSize(dst.Type(), len(items))
+		for _, item := range items {
+			i := strings.Index(item, "=")
+			if i == -1 {
+				return decodeError(key, s, dst.Type(), fmt.Errorf("expect k=v but get %q", item))
+			}
+			k := strings.TrimSpace(item[:i])
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := setString(joinKey(key, k), strings.TrimSpace(item[i+1:]), hooks, v); err != nil {
+				return err
+			}
+			ret.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), v)
+		}
+		dst.Set(ret)
+		return nil
+	}
+	return decodeError(key, s, dst.Type(), errors.New("split requires slice or map"))
+}
+
+// 获得slice或map类型的属性值，属性值为字符串时使用sep拆分
+// param: props 属性
+// param: key 属性key
+// param: sep 分隔符
+// param: result slice或map的指针
+func getSplitValue(props Properties, key string, sep string, result interface{}) error {
+	var raw interface{}
+	if err := props.GetValue(key, &raw); err != nil {
+		return err
+	}
+	if s, ok := raw.(string); ok {
+		return splitDecode(key, s, sep, getDecodeHooks(props), reflect.ValueOf(result).Elem())
+	}
+	return props.GetValue(key, result)
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"io"
+	"sort"
+)
+
+func (ctx *DefaultProperties) Has(key string) bool {
+	_, err := ctx.load().lookup(key)
+	return err == nil
+}
+
+func (ctx *DefaultProperties) Keys(prefix string) []string {
+	v, err := ctx.load().lookup(prefix)
+	if err != nil {
+		return nil
+	}
+	return childKeys(canonicalKey(prefix), v)
+}
+
+func (ctx *DefaultProperties) Sub(prefix string) Properties {
+	return newSubProperties(ctx, prefix)
+}
+
+func (ctx *DefaultProperties) AllSettings() map[string]interface{} {
+	s := ctx.load()
+	if s.value == nil {
+		return map[string]interface{}{}
+	}
+	return settingsValue(map[string]interface{}(*s.value)).(map[string]interface{})
+}
+
+func childKeys(prefix string, v interface{}) []string {
+	var ret []string
+	switch o := v.(type) {
+	case map[string]interface{}:
+		for k := range o {
+			ret = append(ret, joinKey(prefix, k))
+		}
+		sort.Strings(ret)
+	case []interface{}:
+		for i := range o {
+			ret = append(ret, indexKey(prefix, i))
+		}
+	}
+	return ret
+}
+
+// 以prefix为根节点的属性视图，所有操作转换为原属性中的key
+type subProperties struct {
+	parent Properties
+	prefix string
+}
+
+func newSubProperties(parent Properties, prefix string) Properties {
+	if !parent.Has(prefix) {
+		return nil
+	}
+	return &subProperties{
+		parent: parent,
+		prefix: canonicalKey(prefix),
+	}
+}
+
+func (p *subProperties) SetValueReader(r ValueReader) {
+	panic("SubProperties cannot reset ValueReader")
+}
+
+func (p *subProperties) ReadValue(r io.Reader) error {
+	panic("SubProperties cannot ReadValue")
+}
+
+func (p *subProperties) SetValueLoader(l ValueLoader) {
+	panic("SubProperties cannot reset ValueLoader")
+}
+
+func (p *subProperties) Get(key string, defaultValue string) string {
+	return p.parent.Get(joinPath(p.prefix, key), defaultValue)
+}
+
+func (p *subProperties) GetValue(key string, result interface{}) error {
+	return p.parent.GetValue(joinPath(p.prefix, key), result)
+}
+
+func (p *subProperties) getValue(key string, result interface{}, weak bool) error {
+	if weak {
+		return getWeakValue(p.parent, joinPath(p.prefix, key), result)
+	}
+	return p.parent.GetValue(joinPath(p.prefix, key), result)
+}
+
+func (p *subProperties) Explain(key string) []Provenance {
+	ret := p.parent.Explain(joinPath(p.prefix, key))
+	for i := range ret {
+		ret[i].Key = p.relative(ret[i].Key)
+	}
+	return ret
+}
+
+func (p *subProperties) Has(key string) bool {
+	return p.parent.Has(joinPath(p.prefix, key))
+}
+
+func (p *subProperties) Keys(prefix string) []string {
+	ret := p.parent.Keys(joinPath(p.prefix, prefix))
+	for i := range ret {
+		ret[i] = p.relative(ret[i])
+	}
+	return ret
+}
+
+func (p *subProperties) Sub(prefix string) Properties {
+	return newSubProperties(p.parent, joinPath(p.prefix, prefix))
+}
+
+func (p *subProperties) AllSettings() map[string]interface{} {
+	// AllSettings返回的是副本，可以直接使用其子节点
+	v, _ := lookupValue(p.parent.AllSettings(), p.prefix)
+	if ret, ok := v.(map[string]interface{}); ok {
+		return ret
+	}
+	return map[string]interface{}{}
+}
+
+// 将原属性中的key转换为相对于prefix的key
+func (p *subProperties) relative(key string) string {
+	if !isSubKey(key, p.prefix) {
+		return key
+	}
+	key = key[len(p.prefix):]
+	if len(key) > 0 && key[0] == '.' {
+		key = key[1:]
+	}
+	return key
+}
@@ -8,6 +8,7 @@ package test
 import (
 	"github.com/xfali/fig"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -39,4 +40,99 @@ func BenchmarkGet(b *testing.B) {
 			}
 		}
 	})
+
+	type dataSource struct {
+		DriverName  string
+		DriverInfo  string
+		MaxConn     int
+		MaxIdleConn int
+	}
+	loaders := map[string]fig.ValueLoader{
+		"decode":    fig.NewYamlLoader(),
+		"roundtrip": roundTripLoader{fig.NewYamlLoader()},
+	}
+	for name, loader := range loaders {
+		config := fig.New(fig.SetValueLoader(loader))
+		err := config.ReadValue(strings.NewReader(test_yaml_str))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run("GetValue "+name+" scalar", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var v int
+				if err := config.GetValue("DataSources.default.MaxConn", &v); err != nil || v != 1000 {
+					b.Fatal("MaxConn not match ", v, err)
+				}
+			}
+		})
+
+		b.Run("GetValue "+name+" struct", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v := dataSource{}
+				if err := config.GetValue("DataSources.default", &v); err != nil || v.MaxConn != 1000 {
+					b.Fatal("DataSources.default not match ", v, err)
+				}
+			}
+		})
+	}
+}
+
+// 每次读取都加锁的属性，用于与无锁的快照读取对比
+type mutexProperties struct {
+	lock sync.Mutex
+	fig.Properties
+}
+
+func (p *mutexProperties) Get(key string, defaultValue string) string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.Properties.Get(key, defaultValue)
+}
+
+func (p *mutexProperties) GetValue(key string, result interface{}) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.Properties.GetValue(key, result)
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(test_yaml_str))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	props := []struct {
+		name string
+		fig.Properties
+	}{
+		{"snapshot", config},
+		{"mutex", &mutexProperties{Properties: config}},
+	}
+	for _, p := range props {
+		name, prop := p.name, p.Properties
+		b.Run("Get "+name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if v := prop.Get("DataSources.default.MaxConn", ""); v != "1000" {
+						b.Error("expect 1000 but get ", v)
+						return
+					}
+				}
+			})
+		})
+
+		b.Run("GetValue "+name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					var v int
+					if err := prop.GetValue("DataSources.default.MaxConn", &v); err != nil || v != 1000 {
+						b.Error("expect 1000 but get ", v, err)
+						return
+					}
+				}
+			})
+		})
+	}
 }
@@ -0,0 +1,133 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+)
+
+func TestOnChange(t *testing.T) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(test_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsChanged, portChanged, rootChanged := 0, 0, 0
+	var oldDs, newDs interface{}
+	config.OnChange("DataSources.default", func(old, new interface{}) {
+		dsChanged++
+		oldDs, newDs = old, new
+	})
+	config.OnChange("ServerPort", func(old, new interface{}) {
+		portChanged++
+	})
+	config.OnChange("", func(old, new interface{}) {
+		rootChanged++
+	})
+
+	t.Run("same value", func(t *testing.T) {
+		err := config.ReadValue(strings.NewReader(test_yaml_str))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dsChanged != 0 || portChanged != 0 || rootChanged != 0 {
+			t.Fatal("expect no change but get ", dsChanged, portChanged, rootChanged)
+		}
+	})
+
+	t.Run("subtree changed", func(t *testing.T) {
+		err := config.ReadValue(strings.NewReader(strings.Replace(test_yaml_str, "MaxConn: 1000", "MaxConn: 2000", 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dsChanged != 1 || portChanged != 0 || rootChanged != 1 {
+			t.Fatal("expect DataSources.default changed only but get ", dsChanged, portChanged, rootChanged)
+		}
+		if oldDs.(map[string]interface{})["MaxConn"] == newDs.(map[string]interface{})["MaxConn"] {
+			t.Fatal("expect MaxConn changed")
+		}
+		if v := config.Get("DataSources.default.MaxConn", ""); v != "2000" {
+			t.Fatal("expect 2000 but get ", v)
+		}
+	})
+
+	t.Run("key removed", func(t *testing.T) {
+		err := config.ReadValue(strings.NewReader("LogResponse: true"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dsChanged != 2 || portChanged != 1 || rootChanged != 2 {
+			t.Fatal("expect all changed but get ", dsChanged, portChanged, rootChanged)
+		}
+		if newDs != nil {
+			t.Fatal("expect nil but get ", newDs)
+		}
+	})
+}
+
+func TestOnChangeListElem(t *testing.T) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader("servers:\n  - host: a\n  - host: b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := 0, 0
+	config.OnChange("servers[0].host", func(old, new interface{}) {
+		first++
+	})
+	config.OnChange("servers[1].host", func(old, new interface{}) {
+		second++
+	})
+	err = config.ReadValue(strings.NewReader("servers:\n  - host: a\n  - host: c\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 0 || second != 1 {
+		t.Fatal("expect servers[1] changed only but get ", first, second)
+	}
+}
+
+func TestOnChangeSettable(t *testing.T) {
+	config := fig.NewSettableProperties()
+	changed := 0
+	var newV interface{}
+	config.OnChange("a", func(old, new interface{}) {
+		changed++
+		newV = new
+	})
+
+	config.Set("b", 1)
+	if changed != 0 {
+		t.Fatal("expect no change")
+	}
+	config.Set("a", 1)
+	if changed != 1 || newV != 1 {
+		t.Fatal("expect changed but get ", changed, newV)
+	}
+	if v := config.Get("a", ""); v != "1" {
+		t.Fatal("expect 1 but get ", v)
+	}
+	config.Set("a", 2)
+	if v := config.Get("a", ""); v != "2" {
+		t.Fatal("expect 2 but get ", v)
+	}
+	config.Delete("a")
+	if changed != 3 || newV != nil {
+		t.Fatal("expect deleted but get ", changed, newV)
+	}
+}
+
+func TestDiffValue(t *testing.T) {
+	old := fig.Value{"a": map[string]interface{}{"b": 1, "c": 2}, "d": []interface{}{1, 2}}
+	new := fig.Value{"a": map[string]interface{}{"b": 1, "c": 3}, "d": []interface{}{1, 2}, "e": "x"}
+	ret := fig.DiffValue(&old, &new)
+	if len(ret) != 2 || ret[0] != "a.c" || ret[1] != "e" {
+		t.Fatal("expect [a.c e] but get ", ret)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"fmt"
+	"github.com/xfali/fig"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentReadWrite(t *testing.T) {
+	config := fig.NewSettableProperties()
+	err := config.ReadValue(strings.NewReader("ServerPort: 0\nName: test\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				var port int
+				if err := config.GetValue("ServerPort", &port); err != nil || port < 0 {
+					t.Error("expect port but get ", port, err)
+					return
+				}
+				if v := config.Get("Name", ""); v != "test" {
+					t.Error("expect test but get ", v)
+					return
+				}
+				config.Explain("")
+			}
+		}()
+	}
+
+	for i := 1; i <= 100; i++ {
+		err := config.ReadValue(strings.NewReader(fmt.Sprintf("ServerPort: %d\nName: test\n", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Set("Extra", i)
+	}
+	close(stop)
+	wg.Wait()
+
+	if v := fig.GetInt(config)("ServerPort", 0); v != 100 {
+		t.Fatal("expect 100 but get ", v)
+	}
+	if v := fig.GetInt(config)("Extra", 0); v != 100 {
+		t.Fatal("expect 100 but get ", v)
+	}
+	if v := fmt.Sprint((*config.Value)["ServerPort"]); v != "100" {
+		t.Fatal("expect 100 but get ", v)
+	}
+}
+
+func TestConcurrentFillEnv(t *testing.T) {
+	os.Setenv("FIGCONCURRENT_PORT", "8080")
+	defer os.Unsetenv("FIGCONCURRENT_PORT")
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader("Name: test\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type conf struct {
+		Name string `fig:"Name"`
+		Port int    `fig:"port,env=FIGCONCURRENT_PORT"`
+	}
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				v := conf{}
+				if err := fig.Fill(config, &v); err != nil || v.Port != 8080 {
+					t.Error("expect 8080 but get ", v, err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		err := config.ReadValue(strings.NewReader("Name: test\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
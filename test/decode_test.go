@@ -0,0 +1,164 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"fmt"
+	"github.com/xfali/fig"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 只实现序列化和反序列化，用于对比直接转换的结果
+type roundTripLoader struct {
+	loader fig.ValueLoader
+}
+
+func (l roundTripLoader) Serialize(o interface{}) (string, error) {
+	return l.loader.Serialize(o)
+}
+
+func (l roundTripLoader) Deserialize(v string, result interface{}) error {
+	return l.loader.Deserialize(v, result)
+}
+
+const decode_yaml_str = `
+ServerPort: 8080
+Big: 123456789
+Float: 1.5
+Neg: -1
+Name: test
+Enabled: true
+Hosts: [a, b]
+Ports: [1, 2, 3]
+Timeout: 2019-01-02T15:04:05Z
+Servers:
+  - Host: 10.0.0.1
+    Port: 8081
+  - host: 10.0.0.2
+    port: 8082
+Labels:
+  "1": one
+  "2": two
+Inner:
+  Name: inner
+  Level: 3
+`
+
+type decodeInner struct {
+	Name  string
+	Level int `json:"Level"`
+}
+
+type decodeEmbedded struct {
+	Level int
+	Extra string
+}
+
+type decodeStruct struct {
+	ServerPort int
+	Port       string `json:"ServerPort"`
+	Big        string
+	Name       string
+	Enabled    bool
+	Hosts      []string
+	Ports      [2]int
+	Timeout    time.Time
+	Servers    []struct {
+		Host string
+		Port *int
+	}
+	Labels  map[int]string
+	Inner   *decodeInner
+	Ignored string `json:"-"`
+	decodeEmbedded
+}
+
+func TestDecode(t *testing.T) {
+	for _, loader := range []fig.ValueLoader{fig.NewYamlLoader(), fig.NewJsonLoader()} {
+		direct := fig.New(fig.SetValueLoader(loader))
+		roundTrip := fig.New(fig.SetValueLoader(roundTripLoader{loader}))
+		for _, config := range []*fig.DefaultProperties{direct, roundTrip} {
+			if err := config.ReadValue(strings.NewReader(decode_yaml_str)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		targets := map[string]func() interface{}{
+			"ServerPort": func() interface{} { return new(int) },
+			"Big":        func() interface{} { return new(int32) },
+			"Float":      func() interface{} { return new(float32) },
+			"Name":       func() interface{} { return new(string) },
+			"Enabled":    func() interface{} { return new(bool) },
+			"Hosts":      func() interface{} { return new([]string) },
+			"Ports":      func() interface{} { return new([]uint8) },
+			"Timeout":    func() interface{} { return new(time.Time) },
+			"Servers":    func() interface{} { return new([]map[string]interface{}) },
+			"Labels":     func() interface{} { return new(map[string]string) },
+			"Inner":      func() interface{} { return new(interface{}) },
+			"":           func() interface{} { return new(decodeStruct) },
+		}
+		// interface{}中的整数直接转换时为int64，序列化后反序列化时为float64，只比较文本
+		numbersInInterface := map[string]bool{"Servers": true, "Inner": true}
+		for key, f := range targets {
+			expect, actual := f(), f()
+			expectErr := roundTrip.GetValue(key, expect)
+			actualErr := direct.GetValue(key, actual)
+			if (expectErr == nil) != (actualErr == nil) {
+				t.Fatalf("%T %s expect error %v but get %v", loader, key, expectErr, actualErr)
+			}
+			if numbersInInterface[key] {
+				e, a := reflect.ValueOf(expect).Elem().Interface(), reflect.ValueOf(actual).Elem().Interface()
+				if fmt.Sprint(e) != fmt.Sprint(a) {
+					t.Fatalf("%T %s expect %v but get %v", loader, key, e, a)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(expect, actual) {
+				t.Fatalf("%T %s expect %v but get %v", loader, key, reflect.ValueOf(expect).Elem(), reflect.ValueOf(actual).Elem())
+			}
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	config := fig.New(fig.SetValueLoader(fig.NewJsonLoader()))
+	if err := config.ReadValue(strings.NewReader(decode_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	var i int
+	if err := config.GetValue("Float", &i); err == nil {
+		t.Fatal("expect error but get ", i)
+	}
+	var i8 int8
+	if err := config.GetValue("Big", &i8); err == nil {
+		t.Fatal("expect overflow but get ", i8)
+	}
+	var u uint
+	if err := config.GetValue("Neg", &u); err == nil {
+		t.Fatal("expect error but get ", u)
+	}
+	var s string
+	if err := config.GetValue("ServerPort", &s); err == nil {
+		t.Fatal("expect error but get ", s)
+	}
+	if err := config.GetValue("Name", s); err == nil {
+		t.Fatal("expect error")
+	}
+
+	// 返回的值不能修改配置
+	var m map[string]interface{}
+	if err := config.GetValue("Inner", &m); err != nil {
+		t.Fatal(err)
+	}
+	m["Name"] = "changed"
+	if v := config.Get("Inner.Name", ""); v != "inner" {
+		t.Fatal("expect inner but get ", v)
+	}
+}
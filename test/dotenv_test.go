@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadDotenv(t *testing.T) {
+	env, err := fig.ReadDotenv(strings.NewReader(`
+# comment
+export HOST=localhost
+PORT = 8080 # inline comment
+URL=http://${HOST}:$PORT/path#fragment
+SINGLE='${HOST} \n'
+DOUBLE="line1\nline2 \"${HOST}\" \$HOST"
+MULTI="a
+b"
+DEFAULT=${NOT_EXIST_VAR:-default}
+OUTER=${OUTER_VAR}
+EMPTY=
+`), func(key string) (string, bool) {
+		if key == "OUTER_VAR" {
+			return "outer", true
+		}
+		return "", false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"HOST":    "localhost",
+		"PORT":    "8080",
+		"URL":     "http://localhost:8080/path#fragment",
+		"SINGLE":  "${HOST} \\n",
+		"DOUBLE":  "line1\nline2 \"localhost\" $HOST",
+		"MULTI":   "a\nb",
+		"DEFAULT": "default",
+		"OUTER":   "outer",
+		"EMPTY":   "",
+	}
+	if len(env) != len(expect) {
+		t.Fatal("expect ", expect, " but get ", env)
+	}
+	for k, v := range expect {
+		if env[k] != v {
+			t.Fatalf("key %s expect %q but get %q", k, v, env[k])
+		}
+	}
+
+	_, err = fig.ReadDotenv(strings.NewReader("A=1\nB=\"unterminated\n"), nil)
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	t.Log(err)
+}
+
+func TestEnvFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fig_dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	env1 := filepath.Join(dir, ".env")
+	env2 := filepath.Join(dir, ".env.local")
+	err = ioutil.WriteFile(env1, []byte("DOTENV_TEST_A=file1\nDOTENV_TEST_B=file1\nCONTEXT_TEST_ENV=file1\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(env2, []byte("DOTENV_TEST_B=file2\nDOTENV_TEST_C=${CONTEXT_TEST_ENV}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := `
+A: "{{ env "DOTENV_TEST_A" }}"
+B: "{{.Env.DOTENV_TEST_B}}"
+C: "{{ env "DOTENV_TEST_C" }}"
+D: "{{.Env.CONTEXT_TEST_ENV}}"
+`
+	t.Run("process env first", func(t *testing.T) {
+		config := fig.New(fig.SetEnvFiles(false, env1, env2, filepath.Join(dir, "not_exist")))
+		err := config.ReadValue(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string]string{"A": "file1", "B": "file2", "C": "ONLY FOR TEST", "D": "ONLY FOR TEST"}
+		for k, v := range expect {
+			if ret := config.Get(k, ""); ret != v {
+				t.Fatalf("key %s expect %s but get %s", k, v, ret)
+			}
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		config := fig.New(fig.SetEnvFiles(true, env1, env2))
+		err := config.ReadValue(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string]string{"A": "file1", "B": "file2", "C": "file1", "D": "file1"}
+		for k, v := range expect {
+			if ret := config.Get(k, ""); ret != v {
+				t.Fatalf("key %s expect %s but get %s", k, v, ret)
+			}
+		}
+	})
+}
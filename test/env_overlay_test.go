@@ -0,0 +1,89 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEnvOverlay(t *testing.T) {
+	envs := map[string]string{
+		"FIGTEST_DATASOURCES_DEFAULT_MAXCONN": "10",
+		"figtest_logresponse":                 "false",
+		"FIGTEST_THIS_IS_A_TEST_VALUE":        "false",
+		"FIGTEST_SERVER_MAX_CONN":             "20",
+		"FIGTEST_SERVERS_1_HOST":              "10.0.0.2",
+		"FIGTEST_TAGS":                        "[x, y, z]",
+		"FIGTEST_NOT_EXIST":                   "1",
+		"FIGTEST_VALUE_FLOAT":                 "not a float",
+	}
+	for k, v := range envs {
+		os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range envs {
+			os.Unsetenv(k)
+		}
+	}()
+
+	config := fig.New(fig.SetEnvOverlay("FIGTEST", ""))
+	err := config.ReadValue(strings.NewReader(test_yaml_str + `
+  this_is_a_test_value: true
+  server:
+    max-conn: 100
+  Servers:
+    - Host: 10.0.0.1
+    - Host: 10.0.0.1
+  Tags: [a]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fig.GetInt(config)("DataSources.default.MaxConn", 0); v != 10 {
+		t.Fatal("expect 10 but get ", v)
+	}
+	if v := fig.GetBool(config)("LogResponse", true); v {
+		t.Fatal("expect false but get ", v)
+	}
+	if v := fig.GetBool(config)("this_is_a_test_value", true); v {
+		t.Fatal("expect false but get ", v)
+	}
+	s := map[string]int{}
+	if err := config.GetValue("server", &s); err != nil || s["max-conn"] != 20 {
+		t.Fatal("expect 20 but get ", s, err)
+	}
+	if v := fig.GetInt(config)("server.max-conn", 0); v != 20 {
+		t.Fatal("expect 20 but get ", v)
+	}
+	if v := config.Get("Value.float", ""); v != "not a float" {
+		t.Fatal("expect not a float but get ", v)
+	}
+	if v := config.Get("NOT_EXIST", ""); v != "" {
+		t.Fatal("expect not exist but get ", v)
+	}
+
+	type server struct {
+		Host string
+	}
+	var servers []server
+	if err := config.GetValue("Servers", &servers); err != nil {
+		t.Fatal(err)
+	}
+	if servers[0].Host != "10.0.0.1" || servers[1].Host != "10.0.0.2" {
+		t.Fatal("not match: ", servers)
+	}
+	var tags []string
+	if err := config.GetValue("Tags", &tags); err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 || tags[2] != "z" {
+		t.Fatal("expect [x y z] but get ", tags)
+	}
+}
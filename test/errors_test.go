@@ -0,0 +1,118 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(`
+ServerPort: 8080
+Float: 1.5
+Name: test
+Servers:
+  - Port: 8081
+  - Port: abc
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		var v int
+		err := config.GetValue("NotExist", &v)
+		if !errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("expect ErrKeyNotFound but get ", err)
+		}
+		err = config.GetValue("Servers[5].Port", &v)
+		if !errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("expect ErrKeyNotFound but get ", err)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		var v int
+		err := config.GetValue("Servers[x", &v)
+		var pe *fig.ParseError
+		if !errors.As(err, &pe) || pe.Key != "Servers[x" || pe.Offset != 7 {
+			t.Fatal("expect ParseError but get ", err)
+		}
+		if errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("parse error should not be not found")
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		var v int
+		err := config.GetValue("Name", &v)
+		var de *fig.DecodeError
+		if !errors.As(err, &de) || de.Key != "Name" || de.Raw != "test" || de.Type != reflect.TypeOf(0) {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+		t.Log(err)
+
+		type server struct {
+			Port int
+		}
+		var servers []server
+		err = config.GetValue("Servers", &servers)
+		if !errors.As(err, &de) || de.Key != "Servers[1].Port" || de.Raw != "abc" {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+		t.Log(err)
+
+		var i8 int8
+		err = config.GetValue("ServerPort", &i8)
+		if !errors.As(err, &de) || de.Err == nil {
+			t.Fatal("expect overflow but get ", err)
+		}
+		t.Log(err)
+	})
+
+	t.Run("decode round trip", func(t *testing.T) {
+		c := fig.New(fig.SetValueLoader(roundTripLoader{fig.NewJsonLoader()}))
+		err := c.ReadValue(strings.NewReader("Name: test\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v int
+		err = c.GetValue("Name", &v)
+		var de *fig.DecodeError
+		if !errors.As(err, &de) || de.Key != "Name" || de.Err == nil {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		high := fig.NewSettableProperties()
+		high.Set("Name", "")
+		high.Set("Float", "not a float")
+		merged := fig.MergeProperties(high, config)
+
+		if v := merged.Get("Name", "default"); v != "" {
+			t.Fatal("expect empty but get ", v)
+		}
+		var f float64
+		err := merged.GetValue("Float", &f)
+		var de *fig.DecodeError
+		if !errors.As(err, &de) {
+			t.Fatal("expect DecodeError but get ", err, f)
+		}
+		var port int
+		if err := merged.GetValue("ServerPort", &port); err != nil || port != 8080 {
+			t.Fatal("expect 8080 but get ", port, err)
+		}
+		if err := merged.GetValue("NotExist", &port); !errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("expect ErrKeyNotFound but get ", err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+)
+
+const fill_errors_yaml_str = `
+Name: demo
+Port: abc
+Servers:
+  - Host: 10.0.0.1
+    Port: 80
+  - Host: 10.0.0.2
+    Port: x
+`
+
+type fillErrorsHost struct {
+	Host string `fig:"Host"`
+	Port int    `fig:"Port"`
+}
+
+type fillErrors struct {
+	Name    string           `fig:"Name"`
+	Port    int              `fig:"Port"`
+	Timeout int              `fig:"Timeout"`
+	Servers []fillErrorsHost `fig:"Servers"`
+}
+
+func TestFillErrors(t *testing.T) {
+	config := fig.New()
+	if err := config.ReadValue(strings.NewReader(fill_errors_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("fill", func(t *testing.T) {
+		v := fillErrors{Port: 8080, Timeout: 30}
+		err := fig.Fill(config, &v)
+		var errs fig.Errors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatal("expect 2 errors but get ", err)
+		}
+		t.Log(err)
+		var fe *fig.FieldError
+		if !errors.As(errs[0], &fe) || fe.Field != "Port" || fe.Key != "Port" {
+			t.Fatal("expect Port but get ", errs[0])
+		}
+		if !errors.As(errs[1], &fe) || fe.Field != "Servers[1].Port" || fe.Key != "Servers[1].Port" {
+			t.Fatal("expect Servers[1].Port but get ", errs[1])
+		}
+		var de *fig.DecodeError
+		if !errors.As(err, &de) || de.Raw != "abc" {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+		if errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("missing key should be ignored")
+		}
+
+		if v.Name != "demo" {
+			t.Fatal("expect demo but get ", v.Name)
+		}
+		if v.Port != 8080 {
+			t.Fatal("expect preset 8080 but get ", v.Port)
+		}
+		if v.Timeout != 30 {
+			t.Fatal("expect preset 30 but get ", v.Timeout)
+		}
+		if len(v.Servers) != 2 || v.Servers[0].Port != 80 || v.Servers[1].Host != "10.0.0.2" {
+			t.Fatal("not match: ", v.Servers)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		v := fillErrors{Timeout: 30}
+		err := fig.FillStrict(config, &v)
+		var errs fig.Errors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatal("expect 3 errors but get ", err)
+		}
+		var fe *fig.FieldError
+		if !errors.As(errs[1], &fe) || fe.Field != "Timeout" || !errors.Is(fe, fig.ErrKeyNotFound) {
+			t.Fatal("expect Timeout not found but get ", errs[1])
+		}
+		if v.Timeout != 30 {
+			t.Fatal("expect preset 30 but get ", v.Timeout)
+		}
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		type ok struct {
+			Name string `fig:"Name"`
+			Port int    `fig:"Missing,default=80"`
+		}
+		v := ok{}
+		if err := fig.FillStrict(config, &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Name != "demo" || v.Port != 80 {
+			t.Fatal("not match: ", v)
+		}
+	})
+}
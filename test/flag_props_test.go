@@ -0,0 +1,118 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"flag"
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+)
+
+func TestLoadArgs(t *testing.T) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(test_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := fig.LoadArgs([]string{
+		"--DataSources.default.MaxConn=20",
+		"-LogResponse=false",
+		"--set", "Value.float=2.5",
+		"--set=Env=prod",
+		"--Debug",
+		"--Hosts=[a, b]",
+		"--Version=\"1.10\"",
+		"file.txt",
+		"--msg=hello: world",
+		"--port", "99",
+		"--offset", "-1",
+		"--verbose",
+		"--",
+		"--ServerPort=9090",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := fig.MergeProperties(args, config)
+	if v := fig.GetInt(m)("DataSources.default.MaxConn", 0); v != 20 {
+		t.Fatal("expect 20 but get ", v)
+	}
+	if v := fig.GetBool(m)("LogResponse", true); v {
+		t.Fatal("expect false but get ", v)
+	}
+	if v := fig.GetFloat64(m)("Value.float", 0); v != 2.5 {
+		t.Fatal("expect 2.5 but get ", v)
+	}
+	if v := m.Get("Env", ""); v != "prod" {
+		t.Fatal("expect prod but get ", v)
+	}
+	if v := fig.GetBool(m)("Debug", false); !v {
+		t.Fatal("expect true but get ", v)
+	}
+	if v := m.Get("Version", ""); v != "1.10" {
+		t.Fatal("expect 1.10 but get ", v)
+	}
+	if v := fig.GetInt(m)("ServerPort", 0); v != 8080 {
+		t.Fatal("expect 8080 but get ", v)
+	}
+	if v := m.Get("DataSources.default.DriverName", ""); v != "ONLY FOR TEST" {
+		t.Fatal("expect ONLY FOR TEST but get ", v)
+	}
+	// 非标量的值使用原始字符串
+	if v := m.Get("Hosts", ""); v != "[a, b]" {
+		t.Fatal("expect [a, b] but get ", v)
+	}
+	if v := m.Get("msg", ""); v != "hello: world" {
+		t.Fatal("expect hello: world but get ", v)
+	}
+	if v := fig.GetInt(m)("port", 0); v != 99 {
+		t.Fatal("expect 99 but get ", v)
+	}
+	if v := fig.GetInt(m)("offset", 0); v != -1 {
+		t.Fatal("expect -1 but get ", v)
+	}
+	if v := fig.GetBool(m)("verbose", false); !v {
+		t.Fatal("expect true but get ", v)
+	}
+
+	_, err = fig.LoadArgs([]string{"--set", "novalue"})
+	if err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestLoadFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var kv fig.KeyValueFlag
+	fs.Var(&kv, fig.SetFlagName, "set property key=value")
+	fs.Int("ServerPort", 8080, "server port")
+	fs.String("Env", "dev", "env")
+	err := fs.Parse([]string{"-ServerPort", "9090", "-set", "DataSources.default.MaxConn=30", "-set", "LogResponse=false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := fig.LoadFlagSet(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fig.GetInt(config)("ServerPort", 0); v != 9090 {
+		t.Fatal("expect 9090 but get ", v)
+	}
+	if v := fig.GetInt(config)("DataSources.default.MaxConn", 0); v != 30 {
+		t.Fatal("expect 30 but get ", v)
+	}
+	if v := fig.GetBool(config)("LogResponse", true); v {
+		t.Fatal("expect false but get ", v)
+	}
+	// 未显式设置的参数不作为属性
+	if v := config.Get("Env", ""); v != "" {
+		t.Fatal("expect empty but get ", v)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/xfali/fig"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const hooks_yaml_str = `
+Level: warn
+Levels: [debug, error]
+BadLevel: verbose
+Pattern: ^[a-z]+$
+BadPattern: "["
+TLS:
+  ServerName: example.com
+  MinVersion: 1.2
+  InsecureSkipVerify: true
+`
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+func (l *logLevel) UnmarshalFig(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("level must be string but get %T", v)
+	}
+	switch strings.ToLower(s) {
+	case "debug":
+		*l = levelDebug
+	case "info":
+		*l = levelInfo
+	case "warn":
+		*l = levelWarn
+	case "error":
+		*l = levelError
+	default:
+		return fmt.Errorf("unknown level %q", s)
+	}
+	return nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func decodeRegexp(v interface{}) (interface{}, error) {
+	return regexp.Compile(v.(string))
+}
+
+func decodeTLS(v interface{}) (interface{}, error) {
+	m := v.(map[string]interface{})
+	ret := &tls.Config{}
+	if s, ok := m["ServerName"].(string); ok {
+		ret.ServerName = s
+	}
+	if b, ok := m["InsecureSkipVerify"].(bool); ok {
+		ret.InsecureSkipVerify = b
+	}
+	if ver, ok := m["MinVersion"]; ok {
+		ret.MinVersion = tlsVersions[fmt.Sprint(ver)]
+	}
+	return ret, nil
+}
+
+func TestDecodeHooks(t *testing.T) {
+	config := fig.New(
+		fig.DecodeHook(reflect.TypeOf(""), reflect.TypeOf(&regexp.Regexp{}), decodeRegexp),
+		fig.DecodeHook(reflect.TypeOf(map[string]interface{}{}), reflect.TypeOf(&tls.Config{}), decodeTLS),
+	)
+	if err := config.ReadValue(strings.NewReader(hooks_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("unmarshaler", func(t *testing.T) {
+		var l logLevel
+		if err := config.GetValue("Level", &l); err != nil || l != levelWarn {
+			t.Fatal("expect warn but get ", l, err)
+		}
+		var ls []logLevel
+		if err := config.GetValue("Levels", &ls); err != nil || !reflect.DeepEqual(ls, []logLevel{levelDebug, levelError}) {
+			t.Fatal("expect [debug error] but get ", ls, err)
+		}
+		var lp *logLevel
+		if err := config.GetValue("Level", &lp); err != nil || lp == nil || *lp != levelWarn {
+			t.Fatal("expect warn but get ", lp, err)
+		}
+		var de *fig.DecodeError
+		if err := config.GetValue("BadLevel", &l); !errors.As(err, &de) || de.Key != "BadLevel" {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+		// 未注册DecodeHook的属性同样支持FigUnmarshaler
+		c := fig.New(fig.SetValueLoader(roundTripLoader{fig.NewYamlLoader()}))
+		if err := c.ReadValue(strings.NewReader(hooks_yaml_str)); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.GetValue("Level", &l); err != nil || l != levelWarn {
+			t.Fatal("expect warn but get ", l, err)
+		}
+	})
+
+	t.Run("hook", func(t *testing.T) {
+		var re *regexp.Regexp
+		if err := config.GetValue("Pattern", &re); err != nil || !re.MatchString("abc") || re.MatchString("ABC") {
+			t.Fatal("expect regexp but get ", re, err)
+		}
+		var de *fig.DecodeError
+		if err := config.GetValue("BadPattern", &re); !errors.As(err, &de) || de.Key != "BadPattern" {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+		var c *tls.Config
+		if err := config.GetValue("TLS", &c); err != nil || c.ServerName != "example.com" ||
+			c.MinVersion != tls.VersionTLS12 || !c.InsecureSkipVerify {
+			t.Fatal("expect tls config but get ", c, err)
+		}
+		var s string
+		if err := config.GetValue("Pattern", &s); err != nil || s != "^[a-z]+$" {
+			t.Fatal("expect string but get ", s, err)
+		}
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		type conf struct {
+			Level    logLevel       `fig:"Level"`
+			Default  logLevel       `fig:"NotExist,default=error"`
+			LevelPtr *logLevel      `fig:"Levels[0]"`
+			Levels   []logLevel     `fig:"Levels"`
+			Pattern  *regexp.Regexp `fig:"Pattern"`
+			TLS      *tls.Config    `fig:"TLS"`
+			// 属性存在时使用属性值，不存在时使用default，均通过DecodeHook转换
+			PatternDefault *regexp.Regexp   `fig:"Pattern,default=^x$"`
+			Fallback       *regexp.Regexp   `fig:"NotExist,default=^x$"`
+			Patterns       []*regexp.Regexp `fig:"NotExist,default=^a$;^b$,split=;"`
+		}
+		v := conf{}
+		if err := fig.Fill(config, &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Level != levelWarn || v.Default != levelError || v.LevelPtr == nil || *v.LevelPtr != levelDebug ||
+			len(v.Levels) != 2 || v.Pattern == nil || v.TLS == nil || v.TLS.ServerName != "example.com" {
+			t.Fatal("not match: ", v)
+		}
+		if v.PatternDefault == nil || !v.PatternDefault.MatchString("abc") || v.Fallback == nil || !v.Fallback.MatchString("x") ||
+			len(v.Patterns) != 2 || !v.Patterns[1].MatchString("b") {
+			t.Fatal("not match: ", v.PatternDefault, v.Fallback, v.Patterns)
+		}
+
+		type bad struct {
+			Level logLevel `fig:"BadLevel"`
+		}
+		err := fig.Fill(config, &bad{})
+		var fe *fig.FieldError
+		if !errors.As(err, &fe) || fe.Field != "Level" {
+			t.Fatal("expect FieldError but get ", err)
+		}
+	})
+
+	t.Run("number from", func(t *testing.T) {
+		type scaled int
+		c := fig.New(
+			fig.DecodeHook(reflect.TypeOf(float64(0)), reflect.TypeOf(scaled(0)), func(v interface{}) (interface{}, error) {
+				return scaled(v.(float64) * 10), nil
+			}),
+			fig.DecodeHook(reflect.TypeOf(uint8(0)), reflect.TypeOf(logLevel(0)), func(v interface{}) (interface{}, error) {
+				return logLevel(v.(uint8)), nil
+			}))
+		if err := c.ReadValue(strings.NewReader("lvl: 3\nratio: 1.5\nlevel: 2\nbig: 300\n")); err != nil {
+			t.Fatal(err)
+		}
+		var s scaled
+		if err := c.GetValue("lvl", &s); err != nil || s != 30 {
+			t.Fatal("expect 30 but get ", s, err)
+		}
+		if err := c.GetValue("ratio", &s); err != nil || s != 15 {
+			t.Fatal("expect 15 but get ", s, err)
+		}
+		var l logLevel
+		if err := c.GetValue("level", &l); err != nil || l != logLevel(2) {
+			t.Fatal("expect 2 but get ", l, err)
+		}
+		// 300超出uint8范围，不匹配该DecodeHook，使用FigUnmarshaler
+		if err := c.GetValue("big", &l); err == nil {
+			t.Fatal("expect error but get ", l)
+		}
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		c := fig.New(fig.SetValueReader(fig.NewTomlReader()), fig.SetValueLoader(fig.NewTomlLoader()),
+			fig.DecodeHook(reflect.TypeOf(""), reflect.TypeOf(&regexp.Regexp{}), decodeRegexp))
+		if err := c.ReadValue(strings.NewReader("Pattern = \"^a\"\nLevel = \"info\"\nPort = 80\n")); err != nil {
+			t.Fatal(err)
+		}
+		var re *regexp.Regexp
+		if err := c.GetValue("Pattern", &re); err != nil || !re.MatchString("abc") {
+			t.Fatal("expect regexp but get ", re, err)
+		}
+		var l logLevel
+		if err := c.GetValue("Level", &l); err != nil || l != levelInfo {
+			t.Fatal("expect info but get ", l, err)
+		}
+		if v := fig.GetInt(c)("Port", 0); v != 80 {
+			t.Fatal("expect 80 but get ", v)
+		}
+	})
+}
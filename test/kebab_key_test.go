@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+)
+
+const kebab_yaml_str = `
+server:
+  max-conn: 100
+  read-timeout: 3s
+  1st-host: 10.0.0.1
+  with space: ok
+spring:
+  datasource:
+    driver-class-name: com.mysql.Driver
+    max-idle: 20
+`
+
+func TestKebabKey(t *testing.T) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(kebab_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		cases := map[string]string{
+			"server.max-conn":                      "100",
+			"server.read-timeout":                  "3s",
+			"server.1st-host":                      "10.0.0.1",
+			"server.with space":                    "ok",
+			"spring.datasource.driver-class-name":  "com.mysql.Driver",
+			"spring.datasource.driver-class-name2": "default",
+		}
+		for k, expect := range cases {
+			if v := config.Get(k, "default"); v != expect {
+				t.Fatalf("%s expect %s but get %s", k, expect, v)
+			}
+		}
+		if v := fig.GetInt(config)("spring.datasource.max-idle", 0); v != 20 {
+			t.Fatal("expect 20 but get ", v)
+		}
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		type conf struct {
+			MaxConn int    `fig:"server.max-conn"`
+			px      string `figPx:"spring.datasource"`
+			Driver  string `fig:"driver-class-name"`
+			MaxIdle int    `fig:"max-idle"`
+		}
+		c := conf{}
+		if err := fig.Fill(config, &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.MaxConn != 100 || c.Driver != "com.mysql.Driver" || c.MaxIdle != 20 || c.px != "" {
+			t.Fatal("not match: ", c)
+		}
+
+		type conf2 struct {
+			px      string `figPx:"spring.datasource"`
+			Driver  string `fig:"driver-class-name"`
+			MaxOpen int    `fig:"max-open,default=5"`
+		}
+		c2 := conf2{}
+		if err := fig.FillExWithTagNames(config, &c2, false, []string{fig.TagPrefixName}, []string{fig.TagName}); err != nil {
+			t.Fatal(err)
+		}
+		if c2.Driver != "com.mysql.Driver" || c2.MaxOpen != 5 || c2.px != "" {
+			t.Fatal("not match: ", c2)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		s := fig.NewSettableProperties()
+		s.Set("server", map[string]interface{}{"max-conn": 10})
+		merged := fig.MergeProperties(s, config)
+		if v := merged.Get("server.max-conn", ""); v != "10" {
+			t.Fatal("expect 10 but get ", v)
+		}
+		if v := merged.Get("spring.datasource.max-idle", ""); v != "20" {
+			t.Fatal("expect 20 but get ", v)
+		}
+	})
+}
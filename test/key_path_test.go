@@ -0,0 +1,138 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+)
+
+const key_path_yaml_str = `
+Servers:
+  - Host: 10.0.0.1
+    Port: 8081
+  - Host: 10.0.0.2
+    Port: 8082
+Headers:
+  X-Trace-Id: abc
+  "a.b": dot
+  "k]": bracket
+Matrix:
+  - [1, 2]
+  - [3, 4]
+`
+
+func loadKeyPath(t *testing.T) *fig.DefaultProperties {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(key_path_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestKeyPathGet(t *testing.T) {
+	config := loadKeyPath(t)
+
+	cases := map[string]string{
+		"Servers[0].Host":          "10.0.0.1",
+		"Servers[1].Port":          "8082",
+		`Headers["X-Trace-Id"]`:    "abc",
+		`Headers['X-Trace-Id']`:    "abc",
+		`Headers.X-Trace-Id`:       "abc",
+		`Headers["a.b"]`:           "dot",
+		`Headers.a\.b`:             "dot",
+		`Headers["k]"]`:            "bracket",
+		`Headers.k\]`:              "bracket",
+		"Matrix[1][0]":             "3",
+		`["Servers"][0]["Host"]`:   "10.0.0.1",
+		"Servers[2].Host":          "default",
+		"Servers.Host":             "default",
+		"Servers[0":                "default",
+		`Headers["X-Trace-Id`:      "default",
+		"Servers[-1].Host":         "default",
+		`Headers["not-exist"]`:     "default",
+		"Servers[0].Host.NotExist": "default",
+	}
+	for k, expect := range cases {
+		if v := config.Get(k, "default"); v != expect {
+			t.Fatalf("%s expect %s but get %s", k, expect, v)
+		}
+	}
+}
+
+func TestKeyPathGetValue(t *testing.T) {
+	config := loadKeyPath(t)
+
+	type server struct {
+		Host string
+		Port int
+	}
+	s := server{}
+	if err := config.GetValue("Servers[1]", &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Host != "10.0.0.2" || s.Port != 8082 {
+		t.Fatal("not match: ", s)
+	}
+	if v := fig.GetInt(config)("Servers[0].Port", 0); v != 8081 {
+		t.Fatal("expect 8081 but get ", v)
+	}
+	if err := config.GetValue("Servers[2]", &s); err == nil {
+		t.Fatal("expect error")
+	}
+	if err := config.GetValue("Servers[x]", &s); err == nil {
+		t.Fatal("expect error")
+	}
+
+	merged := fig.MergeProperties(fig.New(), config)
+	if v := merged.Get(`Headers["X-Trace-Id"]`, ""); v != "abc" {
+		t.Fatal("expect abc but get ", v)
+	}
+}
+
+func TestKeyPathFill(t *testing.T) {
+	config := loadKeyPath(t)
+
+	type conf struct {
+		Host  string `fig:"Servers[0].Host"`
+		Port  int    `fig:"Servers[1].Port"`
+		px    string `figPx:"Headers"`
+		Trace string `fig:"[\"X-Trace-Id\"]"`
+		Dot   string `fig:"a\\.b"`
+	}
+	c := conf{}
+	if err := fig.Fill(config, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "10.0.0.1" || c.Port != 8082 || c.Trace != "abc" || c.Dot != "dot" || c.px != "" {
+		t.Fatal("not match: ", c)
+	}
+}
+
+func TestKeyPathExplain(t *testing.T) {
+	config := loadKeyPath(t)
+
+	if ret := config.Explain(`Headers['a.b']`); len(ret) != 1 || ret[0].Key != `Headers["a.b"]` {
+		t.Fatal("not match: ", ret)
+	}
+	if ret := config.Explain("Matrix[0]"); len(ret) != 2 || ret[1].Key != "Matrix[0][1]" {
+		t.Fatal("not match: ", ret)
+	}
+
+	var old, new interface{}
+	config.OnChange(`Headers["X-Trace-Id"]`, func(o, n interface{}) {
+		old, new = o, n
+	})
+	err := config.ReadValue(strings.NewReader(strings.Replace(key_path_yaml_str, "abc", "def", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old != "abc" || new != "def" {
+		t.Fatal("not match: ", old, new)
+	}
+}
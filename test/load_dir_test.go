@@ -0,0 +1,111 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fig_dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"application.yaml": `
+ServerPort: 8080
+LogResponse: true
+Hosts: [a, b]
+DataSources:
+  default:
+    DriverName: "{{.Env.CONTEXT_TEST_ENV}}"
+    MaxConn: 1000
+    MaxIdleConn: 500
+`,
+		"application-dev.json": `{
+  "ServerPort": 8081,
+  "DataSources": {"default": {"MaxConn": 10}}
+}`,
+		"application-prod.yaml": `
+Hosts: [c]
+DataSources:
+  default:
+    MaxIdleConn: 50
+  slave:
+    MaxConn: 5
+`,
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type database struct {
+		DriverName  string
+		MaxConn     int
+		MaxIdleConn int
+	}
+
+	t.Run("profiles", func(t *testing.T) {
+		config, err := fig.LoadDir(dir, "dev", "prod", "not_exist")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetInt(config)("ServerPort", 0); v != 8081 {
+			t.Fatal("expect 8081 but get ", v)
+		}
+		if v := fig.GetBool(config)("LogResponse", false); !v {
+			t.Fatal("expect true but get ", v)
+		}
+		db := database{}
+		if err := config.GetValue("DataSources.default", &db); err != nil {
+			t.Fatal(err)
+		}
+		if db.DriverName != "ONLY FOR TEST" || db.MaxConn != 10 || db.MaxIdleConn != 50 {
+			t.Fatal("not match: ", db)
+		}
+		if v := fig.GetInt(config)("DataSources.slave.MaxConn", 0); v != 5 {
+			t.Fatal("expect 5 but get ", v)
+		}
+		var hosts []string
+		if err := config.GetValue("Hosts", &hosts); err != nil || len(hosts) != 1 || hosts[0] != "c" {
+			t.Fatal("expect [c] but get ", hosts, err)
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv(fig.ProfilesEnvName, "dev")
+		defer os.Unsetenv(fig.ProfilesEnvName)
+
+		config, err := fig.LoadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db := database{}
+		if err := config.GetValue("DataSources.default", &db); err != nil {
+			t.Fatal(err)
+		}
+		if db.MaxConn != 10 || db.MaxIdleConn != 500 {
+			t.Fatal("not match: ", db)
+		}
+	})
+
+	t.Run("empty dir", func(t *testing.T) {
+		_, err := fig.LoadDir(filepath.Join(dir, "not_exist"))
+		if err == nil {
+			t.Fatal("expect error")
+		}
+		t.Log(err)
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDeepMerge(t *testing.T) {
+	high := fig.New()
+	err := high.ReadValue(strings.NewReader(`
+ServerPort: 9090
+Hosts: [c]
+Servers:
+  - Host: 10.0.0.3
+DataSources:
+  default:
+    MaxConn: 10
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	low := fig.NewSettableProperties()
+	err = low.ReadValue(strings.NewReader(test_yaml_str + `
+  Hosts: [a, b]
+  Servers:
+    - Host: 10.0.0.1
+      Port: 8081
+    - Host: 10.0.0.2
+      Port: 8082
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type server struct {
+		Host string
+		Port int
+	}
+
+	t.Run("replace", func(t *testing.T) {
+		config, err := fig.DeepMergeProperties(fig.ListReplace, high, low)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test := TestStruct2{}
+		if err := fig.FillEx(config, &test, true); err != nil {
+			t.Fatal(err)
+		}
+		if *test.MaxIdleConn != 500 || test.DvrName != "ONLY FOR TEST" {
+			t.Fatal("not match: ", test)
+		}
+		if v := fig.GetInt(config)("DataSources.default.MaxConn", 0); v != 10 {
+			t.Fatal("expect 10 but get ", v)
+		}
+		if v := fig.GetInt(config)("ServerPort", 0); v != 9090 {
+			t.Fatal("expect 9090 but get ", v)
+		}
+		var hosts []string
+		if err := config.GetValue("Hosts", &hosts); err != nil || strings.Join(hosts, ",") != "c" {
+			t.Fatal("expect [c] but get ", hosts, err)
+		}
+	})
+
+	t.Run("append", func(t *testing.T) {
+		config, err := fig.DeepMergeProperties(fig.ListAppend, high, low)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hosts []string
+		if err := config.GetValue("Hosts", &hosts); err != nil || strings.Join(hosts, ",") != "a,b,c" {
+			t.Fatal("expect [a b c] but get ", hosts, err)
+		}
+		var servers []server
+		if err := config.GetValue("Servers", &servers); err != nil || len(servers) != 3 {
+			t.Fatal("expect 3 servers but get ", servers, err)
+		}
+	})
+
+	t.Run("merge by index", func(t *testing.T) {
+		config, err := fig.DeepMergeProperties(fig.ListMergeByIndex, fig.MergeProperties(high), low)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hosts []string
+		if err := config.GetValue("Hosts", &hosts); err != nil || strings.Join(hosts, ",") != "c,b" {
+			t.Fatal("expect [c b] but get ", hosts, err)
+		}
+		var servers []server
+		if err := config.GetValue("Servers", &servers); err != nil || len(servers) != 2 {
+			t.Fatal("expect 2 servers but get ", servers, err)
+		}
+		if servers[0].Host != "10.0.0.3" || servers[0].Port != 8081 || servers[1].Host != "10.0.0.2" {
+			t.Fatal("not match: ", servers)
+		}
+	})
+
+	t.Run("sources unchanged", func(t *testing.T) {
+		var hosts []string
+		if err := low.GetValue("Hosts", &hosts); err != nil || strings.Join(hosts, ",") != "a,b" {
+			t.Fatal("expect [a b] but get ", hosts, err)
+		}
+		var servers []server
+		if err := high.GetValue("Servers", &servers); err != nil || servers[0].Port != 0 {
+			t.Fatal("expect Port 0 but get ", servers, err)
+		}
+	})
+
+	t.Run("weakly typed and hooks", func(t *testing.T) {
+		props := fig.New(fig.SetValueReader(fig.NewPropertiesReader()), fig.SetWeaklyTyped(true),
+			fig.DecodeHook(reflect.TypeOf(""), reflect.TypeOf(&regexp.Regexp{}), decodeRegexp))
+		if err := props.ReadValue(strings.NewReader("port=8080\npattern=^a\n")); err != nil {
+			t.Fatal(err)
+		}
+		merged, err := fig.DeepMergeProperties(fig.ListReplace, high, props)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetInt(merged)("port", -1); v != 8080 {
+			t.Fatal("expect 8080 but get ", v)
+		}
+		var re *regexp.Regexp
+		if err := merged.GetValue("pattern", &re); err != nil || !re.MatchString("abc") {
+			t.Fatal("expect regexp but get ", re, err)
+		}
+	})
+}
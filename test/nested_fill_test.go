@@ -0,0 +1,157 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nested_yaml_str = `
+App:
+  Name: demo
+  Server:
+    Port: 8080
+    Timeout: 30s
+  Log:
+    Level: debug
+  DataSources:
+    default:
+      DriverName: mysql
+      MaxConn: 100
+    backup:
+      DriverName: postgres
+      MaxConn: 10
+  Servers:
+    - Host: 10.0.0.1
+      Port: 80
+    - Host: 10.0.0.2
+      Port: 81
+`
+
+type nestedServer struct {
+	Port    int           `fig:"Port"`
+	Timeout time.Duration `fig:"Timeout"`
+}
+
+type nestedLog struct {
+	Level string `fig:"Log.Level"`
+}
+
+type nestedDataSource struct {
+	DriverName string `fig:"DriverName"`
+	MaxConn    int    `fig:"MaxConn"`
+}
+
+type nestedHost struct {
+	Host string `fig:"Host"`
+	Port int    `fig:"Port"`
+}
+
+type nestedApp struct {
+	x string `figPx:"App"`
+	nestedLog
+	Name        string                       `fig:"Name"`
+	Server      nestedServer                 `fig:"Server"`
+	ServerPtr   *nestedServer                `fig:"Server"`
+	Missing     *nestedServer                `fig:"Missing"`
+	DataSources map[string]*nestedDataSource `fig:"DataSources"`
+	Servers     []nestedHost                 `fig:"Servers"`
+}
+
+func TestNestedFill(t *testing.T) {
+	config := fig.New()
+	if err := config.ReadValue(strings.NewReader(nested_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(t *testing.T, v nestedApp) {
+		if v.Name != "demo" {
+			t.Fatal("expect demo but get ", v.Name)
+		}
+		if v.Server.Port != 8080 || v.Server.Timeout != 30*time.Second {
+			t.Fatal("expect 8080 30s but get ", v.Server)
+		}
+		if v.ServerPtr == nil || v.ServerPtr.Port != 8080 {
+			t.Fatal("expect 8080 but get ", v.ServerPtr)
+		}
+		if v.Missing != nil {
+			t.Fatal("expect nil but get ", v.Missing)
+		}
+		if v.Level != "debug" {
+			t.Fatal("expect debug but get ", v.Level)
+		}
+		if len(v.DataSources) != 2 || v.DataSources["default"].DriverName != "mysql" ||
+			v.DataSources["backup"].MaxConn != 10 {
+			t.Fatal("not match: ", v.DataSources)
+		}
+		if len(v.Servers) != 2 || v.Servers[1].Host != "10.0.0.2" || v.Servers[1].Port != 81 {
+			t.Fatal("not match: ", v.Servers)
+		}
+	}
+
+	t.Run("fill", func(t *testing.T) {
+		v := nestedApp{}
+		if err := fig.Fill(config, &v); err != nil {
+			t.Fatal(err)
+		}
+		check(t, v)
+	})
+
+	t.Run("tag names", func(t *testing.T) {
+		v := nestedApp{}
+		err := fig.FillExWithTagNames(config, &v, false, []string{fig.TagPrefixName}, []string{fig.TagName})
+		var fe *fig.FieldError
+		if !errors.As(err, &fe) || fe.Field != "Missing" || !errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("expect missing but get ", err)
+		}
+		check(t, v)
+	})
+
+	t.Run("sub", func(t *testing.T) {
+		type app struct {
+			Server nestedServer `fig:"Server"`
+		}
+		v := app{}
+		if err := fig.Fill(config.Sub("App"), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Server.Port != 8080 {
+			t.Fatal("expect 8080 but get ", v.Server)
+		}
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		type root struct {
+			x string `figPx:"App.DataSources.backup"`
+			*nestedDataSource
+		}
+		v := root{}
+		if err := fig.Fill(config, &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.nestedDataSource != nil {
+			// 非导出的匿名指针无法分配
+			t.Fatal("expect nil but get ", v.nestedDataSource)
+		}
+
+		type Source = nestedDataSource
+		type root2 struct {
+			x string `figPx:"App.DataSources.backup"`
+			*Source
+		}
+		v2 := root2{}
+		if err := fig.Fill(config, &v2); err != nil {
+			t.Fatal(err)
+		}
+		if v2.Source == nil || v2.DriverName != "postgres" || v2.MaxConn != 10 {
+			t.Fatal("expect postgres but get ", v2.Source)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+const number_yaml_str = `
+Id: 9007199254740993
+MaxInt64: 9223372036854775807
+MinInt64: -9223372036854775808
+MaxUint64: 18446744073709551615
+TooBig: 18446744073709551616
+Float: 1.5
+Exp: 1e3
+Neg: -1
+`
+
+const number_json_str = `{
+  "Id": 9007199254740993,
+  "MaxInt64": 9223372036854775807,
+  "MinInt64": -9223372036854775808,
+  "MaxUint64": 18446744073709551615,
+  "TooBig": 18446744073709551616,
+  "Float": 1.5,
+  "Exp": 1e3,
+  "Neg": -1
+}`
+
+func TestLargeNumber(t *testing.T) {
+	yamlConfig := fig.New()
+	if err := yamlConfig.ReadValue(strings.NewReader(number_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+	jsonConfig := fig.New(fig.SetValueReader(fig.NewJsonReader()), fig.SetValueLoader(fig.NewJsonLoader()))
+	if err := jsonConfig.ReadValue(strings.NewReader(number_json_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]fig.Properties{"yaml": yamlConfig, "json": jsonConfig} {
+		t.Run(name, func(t *testing.T) {
+			if v := fig.GetInt64(config)("Id", 0); v != 9007199254740993 {
+				t.Fatal("expect 9007199254740993 but get ", v)
+			}
+			if v := fig.GetInt64(config)("MaxInt64", 0); v != math.MaxInt64 {
+				t.Fatal("expect MaxInt64 but get ", v)
+			}
+			if v := fig.GetInt64(config)("MinInt64", 0); v != math.MinInt64 {
+				t.Fatal("expect MinInt64 but get ", v)
+			}
+			if v := fig.GetUint64(config)("MaxUint64", 0); v != math.MaxUint64 {
+				t.Fatal("expect MaxUint64 but get ", v)
+			}
+			if v := fig.GetInt(config)("Exp", 0); v != 1000 {
+				t.Fatal("expect 1000 but get ", v)
+			}
+			if v := config.Get("Id", ""); v != "9007199254740993" {
+				t.Fatal("expect 9007199254740993 but get ", v)
+			}
+
+			var de *fig.DecodeError
+			var i64 int64
+			if err := config.GetValue("MaxUint64", &i64); !errors.As(err, &de) || de.Err == nil {
+				t.Fatal("expect overflow but get ", i64, err)
+			}
+			var u64 uint64
+			if err := config.GetValue("TooBig", &u64); !errors.As(err, &de) {
+				t.Fatal("expect overflow but get ", u64, err)
+			}
+			if err := config.GetValue("Neg", &u64); !errors.As(err, &de) {
+				t.Fatal("expect error but get ", u64, err)
+			}
+			var i int
+			if err := config.GetValue("Float", &i); !errors.As(err, &de) {
+				t.Fatal("expect error but get ", i, err)
+			}
+
+			type ids struct {
+				Id        int64  `fig:"Id"`
+				MaxUint64 uint64 `fig:"MaxUint64"`
+			}
+			v := ids{}
+			if err := fig.Fill(config, &v); err != nil {
+				t.Fatal(err)
+			}
+			if v.Id != 9007199254740993 || v.MaxUint64 != math.MaxUint64 {
+				t.Fatal("not match: ", v)
+			}
+
+			m := map[string]interface{}{}
+			if err := config.GetValue("", &m); err != nil {
+				t.Fatal(err)
+			}
+			for _, all := range []map[string]interface{}{m, config.AllSettings()} {
+				if v, ok := all["Id"].(int64); !ok || v != 9007199254740993 {
+					t.Fatalf("expect int64 9007199254740993 but get %T %v", all["Id"], all["Id"])
+				}
+				if v, ok := all["MaxUint64"].(uint64); !ok || v != math.MaxUint64 {
+					t.Fatalf("expect uint64 MaxUint64 but get %T %v", all["MaxUint64"], all["MaxUint64"])
+				}
+				if v, ok := all["Neg"].(int64); !ok || v != -1 {
+					t.Fatalf("expect int64 -1 but get %T %v", all["Neg"], all["Neg"])
+				}
+				if v, ok := all["Float"].(float64); !ok || v != 1.5 {
+					t.Fatalf("expect float64 1.5 but get %T %v", all["Float"], all["Float"])
+				}
+			}
+		})
+	}
+
+	t.Run("args", func(t *testing.T) {
+		config, err := fig.LoadArgs([]string{"--Id=9007199254740993"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetInt64(config)("Id", 0); v != 9007199254740993 {
+			t.Fatal("expect 9007199254740993 but get ", v)
+		}
+	})
+
+	t.Run("env overlay", func(t *testing.T) {
+		os.Setenv("FIGNUMBER_ID", "9007199254740995")
+		defer os.Unsetenv("FIGNUMBER_ID")
+		config := fig.New(fig.SetEnvOverlay("FIGNUMBER", ""))
+		if err := config.ReadValue(strings.NewReader(number_yaml_str)); err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetInt64(config)("Id", 0); v != 9007199254740995 {
+			t.Fatal("expect 9007199254740995 but get ", v)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+)
+
+func checkPropertiesFile(t *testing.T, config fig.Properties) {
+	v := config.Get("LogResponse", "")
+	if v != "true" {
+		t.Fatal("expect true but get ", v)
+	}
+	v = config.Get("Value.float", "")
+	if v != "1.5" {
+		t.Fatal("expect 1.5 but get ", v)
+	}
+	v = config.Get("Value.floatHaveEnv", "")
+	if v != "1.1" {
+		t.Fatal("expect 1.1 but get ", v)
+	}
+	v = config.Get("DataSources.default.DriverName", "")
+	if v != "ONLY FOR TEST" {
+		t.Fatal("expect ONLY FOR TEST but get ", v)
+	}
+	v = config.Get("DataSources.default.DriverInfo", "")
+	if v != "root:123@tcp(localhost:3306)/test?charset=utf8" {
+		t.Fatal("expect root:123@tcp(localhost:3306)/test?charset=utf8 but get ", v)
+	}
+	v = config.Get("DataSources.default.MaxConn", "")
+	if v != "1000" {
+		t.Fatal("expect 1000 but get ", v)
+	}
+}
+
+func TestPropertiesReader(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		config, err := fig.LoadPropertiesFile("config.properties")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPropertiesFile(t, config)
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		config := fig.New(fig.SetValueReader(fig.NewPropertiesReader()))
+		err := config.ReadValue(strings.NewReader(`
+a.key\ with\ space = value
+a.colon\:key=x\ty
+  # comment \
+a.unicode=中文😀
+a.escaped=\u4e2d\uD83D\uDE00
+a.multi=first, \
+        second, \\
+a.last=end\\\\
+a.empty
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]string{}
+		err = config.GetValue("a", &m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string]string{
+			"key with space": "value",
+			"colon:key":      "x\ty",
+			"unicode":        "中文😀",
+			"escaped":        "中😀",
+			"multi":          "first, second, \\",
+			"last":           "end\\\\",
+			"empty":          "",
+		}
+		if len(m) != len(expect) {
+			t.Fatal("expect ", expect, " but get ", m)
+		}
+		for k, v := range expect {
+			if m[k] != v {
+				t.Fatalf("key %s expect %q but get %q", k, v, m[k])
+			}
+		}
+	})
+
+	t.Run("leaf and children", func(t *testing.T) {
+		config := fig.New(fig.SetValueReader(fig.NewPropertiesReader()))
+		err := config.ReadValue(strings.NewReader("log.level=INFO\nlog.level.root=DEBUG\na.b.c=2\na.b=1\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := config.Get("log.level", ""); v != "INFO" {
+			t.Fatal("expect INFO but get ", v)
+		}
+		var s string
+		if err := config.GetValue("log.level", &s); err != nil || s != "INFO" {
+			t.Fatal("expect INFO but get ", s, err)
+		}
+		type logConf struct {
+			Level string
+		}
+		var lc logConf
+		if err := config.GetValue("log", &lc); err != nil || lc.Level != "INFO" {
+			t.Fatal("expect INFO but get ", lc, err)
+		}
+		var f struct {
+			Level string `fig:"log.level"`
+			Root  string `fig:"log.level.root"`
+		}
+		if err := fig.Fill(config, &f); err != nil || f.Level != "INFO" || f.Root != "DEBUG" {
+			t.Fatal("expect INFO DEBUG but get ", f, err)
+		}
+		m := map[string]string{}
+		if err := config.GetValue("log.level", &m); err != nil || m["root"] != "DEBUG" {
+			t.Fatal("expect map but get ", m, err)
+		}
+
+		// 序列化后反序列化时同样使用PropertiesLeafKey的值
+		rt := fig.New(fig.SetValueReader(fig.NewPropertiesReader()), fig.SetValueLoader(roundTripLoader{fig.NewYamlLoader()}))
+		if err := rt.ReadValue(strings.NewReader("log.level=INFO\nlog.level.root=DEBUG\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := rt.GetValue("log.level", &s); err != nil || s != "INFO" {
+			t.Fatal("expect INFO but get ", s, err)
+		}
+		if err := rt.GetValue("log.level", &m); err != nil || m["root"] != "DEBUG" {
+			t.Fatal("expect map but get ", m, err)
+		}
+		if v := config.Get("log.level.root", ""); v != "DEBUG" {
+			t.Fatal("expect DEBUG but get ", v)
+		}
+		if v := fig.GetInt(fig.WeaklyTyped(config))("a.b", 0); v != 1 {
+			t.Fatal("expect 1 but get ", v)
+		}
+		if v := config.Get("a.b.c", ""); v != "2" {
+			t.Fatal("expect 2 but get ", v)
+		}
+
+		err = config.ReadValue(strings.NewReader("a._value=1\n"))
+		if err == nil {
+			t.Fatal("expect reserved key error")
+		}
+		t.Log(err)
+	})
+
+	t.Run("weakly typed", func(t *testing.T) {
+		config, err := fig.LoadPropertiesFile("config.properties")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetInt(config)("DataSources.default.MaxConn", -1); v != 1000 {
+			t.Fatal("expect 1000 but get ", v)
+		}
+		if v := fig.GetBool(config)("LogResponse", false); !v {
+			t.Fatal("expect true but get ", v)
+		}
+	})
+}
+
+func TestIniReader(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		config, err := fig.LoadIniFile("config.ini")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPropertiesFile(t, config)
+		v := config.Get("Env", "")
+		if v != "dev" {
+			t.Fatal("expect dev but get ", v)
+		}
+		if v := fig.GetInt(config)("DataSources.default.MaxConn", -1); v != 1000 {
+			t.Fatal("expect 1000 but get ", v)
+		}
+	})
+
+	t.Run("section", func(t *testing.T) {
+		config := fig.New(fig.SetValueReader(fig.NewIniReader()))
+		err := config.ReadValue(strings.NewReader(`
+[empty]
+[server]
+host=localhost ; not a comment
+port: 8080
+[server.tls]
+enable=true
+[server.host]
+name=web
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := config.Get("server.host", ""); v != "localhost ; not a comment" {
+			t.Fatal("expect localhost ; not a comment but get ", v)
+		}
+		if v := config.Get("server.host.name", ""); v != "web" {
+			t.Fatal("expect web but get ", v)
+		}
+		if v := config.Get("server.tls.enable", ""); v != "true" {
+			t.Fatal("expect true but get ", v)
+		}
+		m := map[string]interface{}{}
+		if err := config.GetValue("empty", &m); err != nil || len(m) != 0 {
+			t.Fatal("expect empty section but get ", m, err)
+		}
+	})
+
+	t.Run("malformed section", func(t *testing.T) {
+		config := fig.New(fig.SetValueReader(fig.NewIniReader()))
+		err := config.ReadValue(strings.NewReader("[server\nhost=localhost\n"))
+		if err == nil {
+			t.Fatal("expect error")
+		}
+		t.Log(err)
+	})
+}
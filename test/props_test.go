@@ -244,7 +244,7 @@ func TestFileAll(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if ret["ServerPort"].(float64) != 8080 {
+		if ret["ServerPort"].(int64) != 8080 {
 			t.Fatal("expect ServerPort but get ", ret["ServerPort"])
 		}
 		t.Log("value:", ret)
@@ -275,7 +275,7 @@ func TestFileAll(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if ret["ServerPort"].(float64) != 8080 {
+		if ret["ServerPort"].(int64) != 8080 {
 			t.Fatal("expect ServerPort but get ", ret["ServerPort"])
 		}
 		t.Log("value:", ret)
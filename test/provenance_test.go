@@ -0,0 +1,154 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func explainOne(t *testing.T, config fig.Properties, key string) fig.Provenance {
+	ret := config.Explain(key)
+	if len(ret) != 1 {
+		t.Fatalf("expect 1 provenance of %s but get %v", key, ret)
+	}
+	t.Log(ret[0])
+	return ret[0]
+}
+
+func checkPosition(t *testing.T, p fig.Provenance, name string, line, column int) {
+	if p.Source.Name != name || p.Source.Line != line || p.Source.Column != column {
+		t.Fatalf("expect %s:%d:%d but get %s", name, line, column, p.Source)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		config, err := fig.LoadYamlFile("config.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosition(t, explainOne(t, config, "ServerPort"), "config.yaml", 7, 1)
+		checkPosition(t, explainOne(t, config, "DataSources.default.MaxConn"), "config.yaml", 21, 5)
+		if ret := config.Explain("DataSources"); len(ret) != 9 {
+			t.Fatal("expect 9 leaves but get ", len(ret))
+		}
+		if ret := config.Explain("NotExist"); len(ret) != 0 {
+			t.Fatal("expect empty but get ", ret)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		config, err := fig.LoadJsonFile("config.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosition(t, explainOne(t, config, "ServerPort"), "config.json", 8, 3)
+		checkPosition(t, explainOne(t, config, "Value.float"), "config.json", 10, 5)
+	})
+
+	t.Run("yaml list and anchor", func(t *testing.T) {
+		config := fig.New(fig.SetSourceName("inline"))
+		err := config.ReadValue(strings.NewReader(`base: &base
+  Port: 80
+Servers:
+  - <<: *base
+    Host: a
+  - b
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosition(t, explainOne(t, config, "Servers[0].Host"), "inline", 5, 5)
+		checkPosition(t, explainOne(t, config, "Servers[0].Port"), "inline", 2, 3)
+		checkPosition(t, explainOne(t, config, "Servers[1]"), "inline", 6, 5)
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		config, err := fig.LoadPropertiesFile("config.properties")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosition(t, explainOne(t, config, "DataSources.default.DriverInfo"), "config.properties", 10, 1)
+	})
+
+	t.Run("env overlay", func(t *testing.T) {
+		os.Setenv("FIGEXPLAIN_SERVERPORT", "9090")
+		defer os.Unsetenv("FIGEXPLAIN_SERVERPORT")
+
+		f, err := os.Open("config.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		config := fig.New(fig.SetEnvOverlay("FIGEXPLAIN", "_"), fig.SetSourceName("config.yaml"))
+		err = config.ReadValue(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := explainOne(t, config, "ServerPort")
+		if p.Source.Name != "env FIGEXPLAIN_SERVERPORT" || len(p.Overridden) != 1 || p.Overridden[0].Line != 7 {
+			t.Fatal("not match: ", p)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fig_explain")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		err = ioutil.WriteFile(filepath.Join(dir, "application.yaml"), []byte("ServerPort: 8080\nHosts: [a, b]\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, "application-dev.json"), []byte(`{"ServerPort": 8081, "Hosts": ["c"]}`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := fig.LoadDir(dir, "dev")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := explainOne(t, config, "ServerPort")
+		if p.Source.File != filepath.Join(dir, "application-dev.json") || len(p.Overridden) != 1 ||
+			p.Overridden[0].File != filepath.Join(dir, "application.yaml") {
+			t.Fatal("not match: ", p)
+		}
+		if ret := config.Explain("Hosts"); len(ret) != 1 || len(ret[0].Overridden) != 1 {
+			t.Fatal("expect Hosts[0] only but get ", ret)
+		}
+
+		args, err := fig.LoadArgs([]string{"--ServerPort=9090"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := fig.NewSettableProperties()
+		s.Set("ServerPort", 1)
+
+		p = explainOne(t, fig.MergeProperties(s, args, config), "ServerPort")
+		if p.Source.Name != "set" || len(p.Overridden) != 3 || p.Overridden[2].Name != "args" {
+			t.Fatal("not match: ", p)
+		}
+
+		m, err := fig.DeepMergeProperties(fig.ListAppend, args, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p = explainOne(t, m, "ServerPort")
+		if p.Source.Name != "args" || len(p.Overridden) != 2 {
+			t.Fatal("not match: ", p)
+		}
+		p = explainOne(t, m, "Hosts[0]")
+		if p.Source.Line != 1 || len(p.Overridden) != 1 {
+			t.Fatal("not match: ", p)
+		}
+	})
+}
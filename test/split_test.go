@@ -0,0 +1,133 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const split_yaml_str = `
+Hosts: [a, b]
+HostsStr: "a, b ,c"
+Ports: [80, 443]
+PortsStr: 80,443
+Labels:
+  env: dev
+  port: 8080
+LabelsStr: env=dev, zone = cn
+SemiHosts: a;b;c
+Timeouts: 1s,2m
+Empty: ""
+`
+
+func TestSplit(t *testing.T) {
+	config := fig.New()
+	if err := config.ReadValue(strings.NewReader(split_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("slice", func(t *testing.T) {
+		if v := fig.GetStringSlice(config)("Hosts", nil); !reflect.DeepEqual(v, []string{"a", "b"}) {
+			t.Fatal("expect [a b] but get ", v)
+		}
+		if v := fig.GetStringSlice(config)("HostsStr", nil); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+			t.Fatal("expect [a b c] but get ", v)
+		}
+		if v := fig.GetStringSlice(config)("Empty", nil); v == nil || len(v) != 0 {
+			t.Fatal("expect empty but get ", v)
+		}
+		if v := fig.GetStringSlice(config)("NotExist", []string{"x"}); !reflect.DeepEqual(v, []string{"x"}) {
+			t.Fatal("expect default but get ", v)
+		}
+		if v := fig.GetIntSlice(config)("Ports", nil); !reflect.DeepEqual(v, []int{80, 443}) {
+			t.Fatal("expect [80 443] but get ", v)
+		}
+		if v := fig.GetIntSlice(config)("PortsStr", nil); !reflect.DeepEqual(v, []int{80, 443}) {
+			t.Fatal("expect [80 443] but get ", v)
+		}
+		if v := fig.GetIntSlice(config)("HostsStr", nil); v != nil {
+			t.Fatal("expect default but get ", v)
+		}
+	})
+
+	t.Run("map", func(t *testing.T) {
+		if v := fig.GetStringMapString(config)("Labels", nil); !reflect.DeepEqual(v, map[string]string{"env": "dev", "port": "8080"}) {
+			t.Fatal("expect map but get ", v)
+		}
+		if v := fig.GetStringMapString(config)("LabelsStr", nil); !reflect.DeepEqual(v, map[string]string{"env": "dev", "zone": "cn"}) {
+			t.Fatal("expect map but get ", v)
+		}
+		if v := fig.GetStringMap(config)("Labels", nil); v["env"] != "dev" || v["port"] != int64(8080) {
+			t.Fatal("expect map but get ", v)
+		}
+		if v := fig.GetStringMap(config)("LabelsStr", nil); !reflect.DeepEqual(v, map[string]interface{}{"env": "dev", "zone": "cn"}) {
+			t.Fatal("expect map but get ", v)
+		}
+		if v := fig.GetStringMapString(config)("HostsStr", nil); v != nil {
+			t.Fatal("expect default but get ", v)
+		}
+	})
+
+	t.Run("env overlay", func(t *testing.T) {
+		os.Setenv("FIGSPLIT_HOSTS", "h1,h2")
+		defer os.Unsetenv("FIGSPLIT_HOSTS")
+		c := fig.New(fig.SetEnvOverlay("FIGSPLIT", ""))
+		if err := c.ReadValue(strings.NewReader(split_yaml_str)); err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetStringSlice(c)("Hosts", nil); !reflect.DeepEqual(v, []string{"h1", "h2"}) {
+			t.Fatal("expect [h1 h2] but get ", v)
+		}
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		type hosts struct {
+			Hosts     []string          `fig:"Hosts,split"`
+			HostsStr  []string          `fig:"HostsStr,split=,"`
+			SemiHosts []string          `fig:"SemiHosts,split=;"`
+			Ports     []int             `fig:"PortsStr,split"`
+			Timeouts  []time.Duration   `fig:"Timeouts,split"`
+			Labels    map[string]string `fig:"LabelsStr,split"`
+			Default   []int             `fig:"NotExist,default=1;2,split=;"`
+		}
+		v := hosts{}
+		if err := fig.Fill(config, &v); err != nil {
+			t.Fatal(err)
+		}
+		expect := hosts{
+			Hosts:     []string{"a", "b"},
+			HostsStr:  []string{"a", "b", "c"},
+			SemiHosts: []string{"a", "b", "c"},
+			Ports:     []int{80, 443},
+			Timeouts:  []time.Duration{time.Second, 2 * time.Minute},
+			Labels:    map[string]string{"env": "dev", "zone": "cn"},
+			Default:   []int{1, 2},
+		}
+		if !reflect.DeepEqual(v, expect) {
+			t.Fatal("expect ", expect, " but get ", v)
+		}
+
+		v = hosts{}
+		if err := fig.FillExWithTagNames(config, &v, false, []string{fig.TagPrefixName}, []string{fig.TagName}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, expect) {
+			t.Fatal("expect ", expect, " but get ", v)
+		}
+
+		type badPort struct {
+			Ports []int `fig:"HostsStr,split"`
+		}
+		if err := fig.FillExWithTagNames(config, &badPort{}, false, []string{fig.TagPrefixName}, []string{fig.TagName}); err == nil {
+			t.Fatal("expect error")
+		}
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sub_yaml_str = `
+ServerPort: 8080
+Empty: null
+Servers:
+  - Host: 10.0.0.1
+  - Host: 10.0.0.2
+DataSources:
+  default:
+    DriverName: mysql
+    MaxConn: 1000
+  backup:
+    DriverName: pg
+`
+
+func TestHasKeys(t *testing.T) {
+	config := fig.New()
+	err := config.ReadValue(strings.NewReader(sub_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{"", "ServerPort", "Empty", "Servers[1].Host", "DataSources.default"} {
+		if !config.Has(k) {
+			t.Fatal("expect has ", k)
+		}
+	}
+	for _, k := range []string{"NotExist", "Servers[2]", "ServerPort.x", "Servers["} {
+		if config.Has(k) {
+			t.Fatal("expect not has ", k)
+		}
+	}
+
+	if v := config.Keys(""); !reflect.DeepEqual(v, []string{"DataSources", "Empty", "ServerPort", "Servers"}) {
+		t.Fatal("not match: ", v)
+	}
+	if v := config.Keys("DataSources"); !reflect.DeepEqual(v, []string{"DataSources.backup", "DataSources.default"}) {
+		t.Fatal("not match: ", v)
+	}
+	if v := config.Keys("Servers"); !reflect.DeepEqual(v, []string{"Servers[0]", "Servers[1]"}) {
+		t.Fatal("not match: ", v)
+	}
+	if v := config.Keys("ServerPort"); len(v) != 0 {
+		t.Fatal("expect empty but get ", v)
+	}
+
+	all := config.AllSettings()
+	if len(all) != 4 {
+		t.Fatal("expect 4 but get ", all)
+	}
+	all["ServerPort"] = 1
+	all["DataSources"].(map[string]interface{})["default"] = nil
+	if v := config.Get("ServerPort", ""); v != "8080" {
+		t.Fatal("expect 8080 but get ", v)
+	}
+	if v := config.Get("DataSources.default.DriverName", ""); v != "mysql" {
+		t.Fatal("expect mysql but get ", v)
+	}
+}
+
+func TestSub(t *testing.T) {
+	config := fig.NewSettableProperties()
+	err := config.ReadValue(strings.NewReader(sub_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Sub("NotExist") != nil {
+		t.Fatal("expect nil")
+	}
+	sub := config.Sub("DataSources")
+	if v := sub.Get("default.DriverName", ""); v != "mysql" {
+		t.Fatal("expect mysql but get ", v)
+	}
+	if v := sub.Keys(""); !reflect.DeepEqual(v, []string{"backup", "default"}) {
+		t.Fatal("not match: ", v)
+	}
+	if !sub.Has("backup") || sub.Has("ServerPort") {
+		t.Fatal("Has not match")
+	}
+	if v := sub.AllSettings(); len(v) != 2 {
+		t.Fatal("expect 2 but get ", v)
+	}
+	if ret := sub.Explain("default.MaxConn"); len(ret) != 1 || ret[0].Key != "default.MaxConn" {
+		t.Fatal("not match: ", ret)
+	}
+
+	type dataSource struct {
+		DriverName string `fig:"DriverName"`
+		MaxConn    int    `fig:"MaxConn"`
+	}
+	ds := dataSource{}
+	if err := fig.Fill(sub.Sub("default"), &ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.DriverName != "mysql" || ds.MaxConn != 1000 {
+		t.Fatal("not match: ", ds)
+	}
+
+	servers := config.Sub("Servers")
+	if v := servers.Get("[1].Host", ""); v != "10.0.0.2" {
+		t.Fatal("expect 10.0.0.2 but get ", v)
+	}
+	if v := servers.Keys(""); !reflect.DeepEqual(v, []string{"[0]", "[1]"}) {
+		t.Fatal("not match: ", v)
+	}
+
+	// 视图随原属性变化
+	config.Set("DataSources", map[string]interface{}{"default": map[string]interface{}{"DriverName": "sqlite"}})
+	if v := sub.Get("default.DriverName", ""); v != "sqlite" {
+		t.Fatal("expect sqlite but get ", v)
+	}
+}
+
+func TestMergedIntrospect(t *testing.T) {
+	high := fig.NewSettableProperties()
+	high.Set("ServerPort", 9090)
+	high.Set("Extra", "x")
+	low := fig.New()
+	err := low.ReadValue(strings.NewReader(sub_yaml_str))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := fig.MergeProperties(high, low)
+
+	if !config.Has("Extra") || !config.Has("DataSources.default") || config.Has("NotExist") {
+		t.Fatal("Has not match")
+	}
+	if v := config.Keys(""); !reflect.DeepEqual(v, []string{"DataSources", "Empty", "Extra", "ServerPort", "Servers"}) {
+		t.Fatal("not match: ", v)
+	}
+	all := config.AllSettings()
+	if all["ServerPort"] != int64(9090) || all["Extra"] != "x" || len(all) != 5 {
+		t.Fatal("not match: ", all)
+	}
+	sub := config.Sub("DataSources")
+	if v := sub.Get("backup.DriverName", ""); v != "pg" {
+		t.Fatal("expect pg but get ", v)
+	}
+	if v := sub.AllSettings(); len(v) != 2 {
+		t.Fatal("expect 2 but get ", v)
+	}
+}
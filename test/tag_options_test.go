@@ -0,0 +1,160 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tag_options_yaml_str = `
+Name: demo
+Empty: ""
+Password: secret-value
+Hosts: a;b
+List: [h1, h2]
+Server:
+  Host: 10.0.0.1
+  Port: 8080
+`
+
+type tagServer struct {
+	Host string `fig:"Host"`
+	Port int    `fig:"Port"`
+}
+
+func TestTagOptions(t *testing.T) {
+	// env使用ReadValue时的环境变量
+	os.Setenv("FIGTAG_PORT", "9090")
+	defer os.Unsetenv("FIGTAG_PORT")
+	config := fig.New()
+	if err := config.ReadValue(strings.NewReader(tag_options_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	type options struct {
+		Name      string   `fig:"Name,required"`
+		Desc      string   `fig:"Desc,default=a\\, b"`
+		Port      int      `fig:"Port,env=FIGTAG_PORT,default=80"`
+		Timeout   int      `fig:"Timeout,env=FIGTAG_NOT_EXIST,default=30"`
+		Empty     string   `fig:"Empty,omitempty,default=none"`
+		Hosts     []string `fig:"Hosts,split=;"`
+		Defaults  []string `fig:"NotExist,default=x\\,y,split=,"`
+		List      []string `fig:"List,default=a"`
+		x         string   `figPx:"Server"`
+		tagServer `fig:",inline"`
+	}
+
+	for name, fill := range map[string]func(fig.Properties, interface{}) error{
+		"Fill":       fig.Fill,
+		"FillStrict": fig.FillStrict,
+		"FillEx": func(p fig.Properties, v interface{}) error {
+			return fig.FillEx(p, v, false)
+		},
+		"FillExWithTagNames": func(p fig.Properties, v interface{}) error {
+			return fig.FillExWithTagNames(p, v, false, []string{fig.TagPrefixName}, []string{fig.TagName})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			v := options{}
+			if err := fill(config, &v); err != nil {
+				t.Fatal(err)
+			}
+			expect := options{
+				Name:      "demo",
+				Desc:      "a, b",
+				Port:      9090,
+				Timeout:   30,
+				Empty:     "none",
+				Hosts:     []string{"a", "b"},
+				Defaults:  []string{"x", "y"},
+				List:      []string{"h1", "h2"},
+				tagServer: tagServer{Host: "10.0.0.1", Port: 8080},
+			}
+			if !reflect.DeepEqual(v, expect) {
+				t.Fatal("expect ", expect, " but get ", v)
+			}
+		})
+	}
+
+	t.Run("required", func(t *testing.T) {
+		type required struct {
+			Name    string `fig:"Name"`
+			Missing string `fig:"Missing,required"`
+		}
+		v := required{}
+		err := fig.Fill(config, &v)
+		var fe *fig.FieldError
+		if !errors.As(err, &fe) || fe.Field != "Missing" || !errors.Is(err, fig.ErrKeyNotFound) {
+			t.Fatal("expect required error but get ", err)
+		}
+		if v.Name != "demo" {
+			t.Fatal("expect demo but get ", v.Name)
+		}
+	})
+
+	t.Run("env file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fig_tag_env")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, ".env")
+		if err := ioutil.WriteFile(file, []byte("FIGTAG_FILE_PORT=7070\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		c := fig.New(fig.SetEnvFiles(false, file))
+		if err := c.ReadValue(strings.NewReader(tag_options_yaml_str)); err != nil {
+			t.Fatal(err)
+		}
+
+		type env struct {
+			Port    int `fig:"NotExist,env=FIGTAG_FILE_PORT,default=80"`
+			ProcEnv int `fig:"NotExist,env=FIGTAG_PORT"`
+		}
+		for _, p := range []fig.Properties{c, c.Sub("Server"), fig.MergeProperties(fig.NewSettableProperties(), c)} {
+			v := env{}
+			if err := fig.Fill(p, &v); err != nil {
+				t.Fatal(err)
+			}
+			if v.Port != 7070 || v.ProcEnv != 9090 {
+				t.Fatal("expect 7070 9090 but get ", v)
+			}
+		}
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		type secret struct {
+			Password int `fig:"Password,secret"`
+		}
+		err := fig.Fill(config, &secret{})
+		var de *fig.DecodeError
+		if !errors.As(err, &de) || strings.Contains(err.Error(), "secret-value") {
+			t.Fatal("expect redacted error but get ", err)
+		}
+		t.Log(err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, tag := range []string{"Name,unknown", "Name,default", "Name,required=true", "Name,env=", "Name,inline"} {
+			v := reflect.New(reflect.StructOf([]reflect.StructField{{
+				Name: "Name",
+				Type: reflect.TypeOf(""),
+				Tag:  reflect.StructTag(`fig:"` + tag + `"`),
+			}}))
+			err := fig.Fill(config, v.Interface())
+			if !errors.Is(err, fig.ErrInvalidTag) {
+				t.Fatal("expect ErrInvalidTag for ", tag, " but get ", err)
+			}
+		}
+	})
+}
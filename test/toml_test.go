@@ -0,0 +1,74 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"testing"
+)
+
+func TestToml(t *testing.T) {
+	config, err := fig.LoadTomlFile("config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		v := config.Get("Value.floatHaveEnv", "0")
+		if v != "1.1" {
+			t.Fatal("expect 1.1 but get ", v)
+		}
+		v = config.Get("DataSources.default.DriverNameGet3", "")
+		if v != "func3_return" {
+			t.Fatal("expect func3_return but get ", v)
+		}
+		port := fig.GetInt(config)("ServerPort", -1)
+		if port != 8080 {
+			t.Fatal("expect 8080 but get ", port)
+		}
+	})
+
+	t.Run("nested table", func(t *testing.T) {
+		type database struct {
+			DriverName      string
+			DriverInfo      string
+			MaxConn         int
+			MaxIdleConn     int
+			ConnMaxLifetime int
+		}
+		ret := map[string]database{}
+		err := config.GetValue("DataSources", &ret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ret["default"].DriverName != "ONLY FOR TEST" || ret["default"].MaxIdleConn != 500 {
+			t.Fatal("not match: ", ret["default"])
+		}
+	})
+
+	t.Run("array of tables", func(t *testing.T) {
+		type server struct {
+			Host string
+			Port int
+			Tags []string
+		}
+		var servers []server
+		err := config.GetValue("Servers", &servers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(servers) != 2 {
+			t.Fatal("expect 2 servers but get ", len(servers))
+		}
+		if servers[1].Host != "127.0.0.2" || servers[1].Port != 8082 || len(servers[0].Tags) != 2 {
+			t.Fatal("not match: ", servers)
+		}
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		test(config, t)
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const typed_yaml_str = `
+Timeout: 1m30s
+TimeoutNs: 1000
+MaxBody: 512MiB
+Cache: 10MB
+Buffer: 1.5K
+Start: 2020-01-02T03:04:05Z
+Day: 2020-01-02
+Endpoint: https://example.com:8443/api?x=1
+Host: 192.168.1.1
+Net: 10.0.0.0/8
+Bad: abc
+`
+
+const typed_toml_str = `
+Timeout = "1m30s"
+MaxBody = "512MiB"
+Start = 2020-01-02T03:04:05Z
+Endpoint = "https://example.com:8443/api?x=1"
+`
+
+func TestTypedGetters(t *testing.T) {
+	config := fig.New()
+	if err := config.ReadValue(strings.NewReader(typed_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("duration", func(t *testing.T) {
+		if v := fig.GetDuration(config)("Timeout", 0); v != 90*time.Second {
+			t.Fatal("expect 1m30s but get ", v)
+		}
+		if v := fig.GetDuration(config)("TimeoutNs", 0); v != time.Microsecond {
+			t.Fatal("expect 1µs but get ", v)
+		}
+		if v := fig.GetDuration(config)("Bad", time.Second); v != time.Second {
+			t.Fatal("expect default but get ", v)
+		}
+	})
+
+	t.Run("byte size", func(t *testing.T) {
+		if v := fig.GetByteSize(config)("MaxBody", 0); v != 512*fig.MiB {
+			t.Fatal("expect 512MiB but get ", v)
+		}
+		if v := fig.GetByteSize(config)("Cache", 0); v != 10*fig.MB {
+			t.Fatal("expect 10MB but get ", v)
+		}
+		if v := fig.GetByteSize(config)("Buffer", 0); v != 1536 {
+			t.Fatal("expect 1536 but get ", v)
+		}
+		if v := (512 * fig.MiB).String(); v != "512MiB" {
+			t.Fatal("expect 512MiB but get ", v)
+		}
+		if _, err := fig.ParseByteSize("10XB"); err == nil {
+			t.Fatal("expect error")
+		}
+		if _, err := fig.ParseByteSize("20000PiB"); err == nil {
+			t.Fatal("expect overflow")
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		if v := fig.GetTime(config)("Start", time.Time{}); !v.Equal(start) {
+			t.Fatal("expect ", start, " but get ", v)
+		}
+		if v := fig.GetTime(config)("Day", time.Time{}); !v.IsZero() {
+			t.Fatal("expect zero but get ", v)
+		}
+		day := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+		if v := fig.GetTime(config, time.RFC3339, "2006-01-02")("Day", time.Time{}); !v.Equal(day) {
+			t.Fatal("expect ", day, " but get ", v)
+		}
+	})
+
+	t.Run("net", func(t *testing.T) {
+		u := fig.GetURL(config)("Endpoint", nil)
+		if u == nil || u.Host != "example.com:8443" || u.Query().Get("x") != "1" {
+			t.Fatal("expect url but get ", u)
+		}
+		if v := fig.GetIP(config)("Host", nil); !v.Equal(net.IPv4(192, 168, 1, 1)) {
+			t.Fatal("expect 192.168.1.1 but get ", v)
+		}
+		if v := fig.GetIP(config)("Bad", nil); v != nil {
+			t.Fatal("expect nil but get ", v)
+		}
+		n := fig.GetCIDR(config)("Net", nil)
+		if n == nil || n.String() != "10.0.0.0/8" || !n.Contains(net.IPv4(10, 1, 2, 3)) {
+			t.Fatal("expect 10.0.0.0/8 but get ", n)
+		}
+
+		var de *fig.DecodeError
+		var ip net.IP
+		if err := config.GetValue("Bad", &ip); !errors.As(err, &de) || de.Key != "Bad" {
+			t.Fatal("expect DecodeError but get ", err)
+		}
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		type typed struct {
+			Timeout  time.Duration `fig:"Timeout"`
+			MaxBody  fig.ByteSize  `fig:"MaxBody"`
+			Start    time.Time     `fig:"Start"`
+			Endpoint *url.URL      `fig:"Endpoint"`
+			Host     net.IP        `fig:"Host"`
+			Net      net.IPNet     `fig:"Net"`
+			Idle     time.Duration `fig:"Idle,default=5s"`
+		}
+		v := typed{}
+		if err := fig.Fill(config, &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Timeout != 90*time.Second || v.MaxBody != 512*fig.MiB || !v.Start.Equal(start) ||
+			v.Endpoint == nil || v.Endpoint.Path != "/api" || v.Host.String() != "192.168.1.1" ||
+			v.Net.String() != "10.0.0.0/8" {
+			t.Fatal("not match: ", v)
+		}
+
+		v = typed{}
+		if err := fig.FillExWithTagNames(config, &v, false, []string{fig.TagPrefixName}, []string{fig.TagName}); err != nil {
+			t.Fatal(err)
+		}
+		if v.Idle != 5*time.Second || v.Timeout != 90*time.Second {
+			t.Fatal("not match: ", v)
+		}
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		c := fig.New(fig.SetValueReader(fig.NewTomlReader()), fig.SetValueLoader(fig.NewTomlLoader()))
+		if err := c.ReadValue(strings.NewReader(typed_toml_str)); err != nil {
+			t.Fatal(err)
+		}
+		if v := fig.GetDuration(c)("Timeout", 0); v != 90*time.Second {
+			t.Fatal("expect 1m30s but get ", v)
+		}
+		if v := fig.GetByteSize(c)("MaxBody", 0); v != 512*fig.MiB {
+			t.Fatal("expect 512MiB but get ", v)
+		}
+		if v := fig.GetTime(c)("Start", time.Time{}); !v.Equal(start) {
+			t.Fatal("expect ", start, " but get ", v)
+		}
+		if v := fig.GetURL(c)("Endpoint", nil); v == nil || v.Port() != "8443" {
+			t.Fatal("expect url but get ", v)
+		}
+	})
+}
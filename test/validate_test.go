@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"strings"
+	"testing"
+	"time"
+)
+
+const validate_yaml_str = `
+ServerPort: 0
+LogLevel: trace
+Name: ""
+Email: test@example
+Endpoint: /api
+Timeout: 100ms
+Password: "123"
+Hosts: []
+Servers:
+  - Host: a
+    Port: 80
+  - Host: b
+    Port: 70000
+Range:
+  Min: 10
+  Max: 1
+`
+
+type validateServer struct {
+	Host string `fig:"Host,nonempty"`
+	Port int    `fig:"Port,min=1,max=65535"`
+}
+
+type validateRange struct {
+	Min int `fig:"Min"`
+	Max int `fig:"Max"`
+}
+
+func (r *validateRange) Validate() error {
+	if r.Min > r.Max {
+		return errors.New("Min must not be greater than Max")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	config := fig.New()
+	if err := config.ReadValue(strings.NewReader(validate_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("violations", func(t *testing.T) {
+		type conf struct {
+			Port     int              `fig:"ServerPort,min=1,max=65535"`
+			Level    string           `fig:"LogLevel,oneof=debug info warn"`
+			Name     string           `fig:"Name,nonempty"`
+			Email    string           `fig:"Email,regex=^[^@]+@[^@]+\\.[a-z]+$"`
+			Endpoint string           `fig:"Endpoint,url"`
+			Timeout  time.Duration    `fig:"Timeout,min=1s"`
+			Password string           `fig:"Password,min=8,secret"`
+			Hosts    []string         `fig:"Hosts,nonempty"`
+			Servers  []validateServer `fig:"Servers"`
+			Range    validateRange    `fig:"Range"`
+		}
+		v := conf{}
+		err := fig.Fill(config, &v)
+		var errs fig.Errors
+		if !errors.As(err, &errs) {
+			t.Fatal("expect Errors but get ", err)
+		}
+		t.Log(err)
+		expect := []struct {
+			field string
+			key   string
+			rule  string
+		}{
+			{"Port", "ServerPort", "min=1"},
+			{"Level", "LogLevel", "oneof=debug info warn"},
+			{"Name", "Name", "nonempty"},
+			{"Email", "Email", `regex=^[^@]+@[^@]+\.[a-z]+$`},
+			{"Endpoint", "Endpoint", "url"},
+			{"Timeout", "Timeout", "min=1s"},
+			{"Password", "Password", "min=8"},
+			{"Hosts", "Hosts", "nonempty"},
+			{"Servers[1].Port", "Servers[1].Port", "max=65535"},
+			{"Range", "Range", "Validate"},
+		}
+		if len(errs) != len(expect) {
+			t.Fatal("expect ", len(expect), " errors but get ", len(errs), err)
+		}
+		for i := range expect {
+			var ve *fig.ValidationError
+			if !errors.As(errs[i], &ve) || ve.Field != expect[i].field || ve.Key != expect[i].key || ve.Rule != expect[i].rule {
+				t.Fatal("expect ", expect[i], " but get ", errs[i])
+			}
+		}
+		if strings.Contains(err.Error(), `"123"`) || strings.Contains(err.Error(), ": 123") {
+			t.Fatal("secret value should not be shown: ", err)
+		}
+		// 校验失败不影响填充
+		if v.Level != "trace" || v.Servers[1].Port != 70000 || v.Range.Min != 10 {
+			t.Fatal("not match: ", v)
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		type conf struct {
+			Level   string           `fig:"LogLevel,oneof=trace debug"`
+			Timeout time.Duration    `fig:"Timeout,min=10ms,max=1s"`
+			Server  *validateServer  `fig:"Servers[0]"`
+			Servers []validateServer `fig:"Servers,min=1,max=2"`
+			Missing *validateServer  `fig:"Missing"`
+		}
+		v := conf{}
+		err := fig.Fill(config, &v)
+		var ve *fig.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "Servers[1].Port" {
+			t.Fatal("expect Servers[1].Port but get ", err)
+		}
+		if v.Server == nil || v.Server.Port != 80 {
+			t.Fatal("expect 80 but get ", v.Server)
+		}
+	})
+
+	t.Run("not applicable", func(t *testing.T) {
+		type conf struct {
+			Level string `fig:"LogLevel,url,min=x"`
+			Port  int    `fig:"ServerPort,regex=\\d+"`
+		}
+		err := fig.Fill(config, &conf{})
+		if !errors.Is(err, fig.ErrInvalidTag) {
+			t.Fatal("expect ErrInvalidTag but get ", err)
+		}
+		type badRegex struct {
+			Level string `fig:"LogLevel,regex=["`
+		}
+		if err := fig.Fill(config, &badRegex{}); !errors.Is(err, fig.ErrInvalidTag) {
+			t.Fatal("expect ErrInvalidTag but get ", err)
+		}
+	})
+
+	t.Run("top level validator", func(t *testing.T) {
+		r := validateRange{}
+		err := fig.Fill(config.Sub("Range"), &r)
+		var ve *fig.ValidationError
+		if !errors.As(err, &ve) || ve.Rule != "Validate" || ve.Err == nil {
+			t.Fatal("expect Validate error but get ", err)
+		}
+	})
+}
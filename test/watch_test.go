@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/fig"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("wait timeout")
+}
+
+func TestWatchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fig_watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(filename, []byte("ServerPort: 8080\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errChan := make(chan error, 10)
+	w, err := fig.WatchYamlFile(filename, fig.WatchInterval(10*time.Millisecond), fig.WatchErrorHandler(func(err error) {
+		errChan <- err
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	config := w.Properties()
+	if v := config.Get("ServerPort", ""); v != "8080" {
+		t.Fatal("expect 8080 but get ", v)
+	}
+
+	t.Run("reload", func(t *testing.T) {
+		err = ioutil.WriteFile(filename, []byte("ServerPort: 9090\nLogResponse: true\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, func() bool {
+			return config.Get("ServerPort", "") == "9090"
+		})
+		if v := fig.GetBool(config)("LogResponse", false); !v {
+			t.Fatal("expect true but get ", v)
+		}
+	})
+
+	t.Run("keep value when reload failed", func(t *testing.T) {
+		err = ioutil.WriteFile(filename, []byte("ServerPort: {{ env \"NOT_EXIST\" }}\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err := <-errChan:
+			t.Log(err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("expect reload error")
+		}
+		if v := config.Get("ServerPort", ""); v != "9090" {
+			t.Fatal("expect 9090 but get ", v)
+		}
+	})
+
+	t.Run("manual reload", func(t *testing.T) {
+		w.Close()
+		err = ioutil.WriteFile(filename, []byte("ServerPort: 10080\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 保证文件修改时间变化
+		now := time.Now().Add(time.Second)
+		os.Chtimes(filename, now, now)
+		reloaded, err := w.Reload()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reloaded {
+			t.Fatal("expect reloaded")
+		}
+		if v := config.Get("ServerPort", ""); v != "10080" {
+			t.Fatal("expect 10080 but get ", v)
+		}
+	})
+
+	t.Run("same size and modify time", func(t *testing.T) {
+		w.Close()
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filename, []byte("ServerPort: 10081\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(filename, info.ModTime(), info.ModTime())
+		reloaded, err := w.Reload()
+		if err != nil || !reloaded {
+			t.Fatal("expect reloaded but get ", reloaded, err)
+		}
+		if v := config.Get("ServerPort", ""); v != "10081" {
+			t.Fatal("expect 10081 but get ", v)
+		}
+		if reloaded, err := w.Reload(); err != nil || reloaded {
+			t.Fatal("expect not reloaded but get ", reloaded, err)
+		}
+	})
+}
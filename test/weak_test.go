@@ -0,0 +1,133 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/fig"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const weak_yaml_str = `
+Port: "8080"
+Big: "9007199254740993"
+Debug: "yes"
+Verbose: "off"
+Enabled: 1
+Ratio: " 1.5 "
+Version: 2
+Flag: true
+Host: 10.0.0.1
+Bad: abc
+Server:
+  Port: "9090"
+`
+
+func TestWeaklyTyped(t *testing.T) {
+	strict := fig.New()
+	if err := strict.ReadValue(strings.NewReader(weak_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+	if v := fig.GetInt(strict)("Port", 0); v != 0 {
+		t.Fatal("expect default but get ", v)
+	}
+
+	weak := fig.New(fig.SetWeaklyTyped(true))
+	if err := weak.ReadValue(strings.NewReader(weak_yaml_str)); err != nil {
+		t.Fatal(err)
+	}
+	settable := fig.NewSettableProperties()
+	settable.Set("Port", "8080")
+	settable.Set("Debug", "yes")
+
+	for name, config := range map[string]fig.Properties{
+		"opt":     weak,
+		"wrapper": fig.WeaklyTyped(strict),
+		"merged":  fig.WeaklyTyped(fig.MergeProperties(settable, strict)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if v := fig.GetInt(config)("Port", 0); v != 8080 {
+				t.Fatal("expect 8080 but get ", v)
+			}
+			if v := fig.GetInt64(config)("Big", 0); v != 9007199254740993 {
+				t.Fatal("expect 9007199254740993 but get ", v)
+			}
+			if v := fig.GetBool(config)("Debug", false); !v {
+				t.Fatal("expect true but get ", v)
+			}
+			if v := fig.GetBool(config)("Verbose", true); v {
+				t.Fatal("expect false but get ", v)
+			}
+			if v := fig.GetBool(config)("Enabled", false); !v {
+				t.Fatal("expect true but get ", v)
+			}
+			if v := fig.GetFloat64(config)("Ratio", 0); v != 1.5 {
+				t.Fatal("expect 1.5 but get ", v)
+			}
+			if v := fig.GetInt(config)("Flag", 0); v != 1 {
+				t.Fatal("expect 1 but get ", v)
+			}
+			if v := fig.GetString(config)("Version", ""); v != "2" {
+				t.Fatal("expect 2 but get ", v)
+			}
+			var hosts []string
+			if err := config.GetValue("Host", &hosts); err != nil || !reflect.DeepEqual(hosts, []string{"10.0.0.1"}) {
+				t.Fatal("expect [10.0.0.1] but get ", hosts, err)
+			}
+			var versions []int
+			if err := config.GetValue("Version", &versions); err != nil || !reflect.DeepEqual(versions, []int{2}) {
+				t.Fatal("expect [2] but get ", versions, err)
+			}
+
+			var de *fig.DecodeError
+			var i int
+			if err := config.GetValue("Bad", &i); !errors.As(err, &de) || de.Key != "Bad" || de.Raw != "abc" || de.Err == nil {
+				t.Fatal("expect DecodeError but get ", err)
+			}
+			t.Log(de)
+			var b bool
+			if err := config.GetValue("Bad", &b); !errors.As(err, &de) || de.Err == nil {
+				t.Fatal("expect DecodeError but get ", err)
+			}
+			var i8 int8
+			if err := config.GetValue("Port", &i8); !errors.As(err, &de) || de.Raw != "8080" {
+				t.Fatal("expect overflow but get ", err)
+			}
+		})
+	}
+
+	t.Run("sub", func(t *testing.T) {
+		if v := fig.GetInt(fig.WeaklyTyped(strict).Sub("Server"))("Port", 0); v != 9090 {
+			t.Fatal("expect 9090 but get ", v)
+		}
+		if v := fig.GetInt(fig.WeaklyTyped(strict.Sub("Server")))("Port", 0); v != 9090 {
+			t.Fatal("expect 9090 but get ", v)
+		}
+		if v := fig.GetInt(strict.Sub("Server"))("Port", 0); v != 0 {
+			t.Fatal("expect default but get ", v)
+		}
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		type server struct {
+			Port  int  `fig:"Port"`
+			Debug bool `fig:"Debug"`
+		}
+		v := server{}
+		if err := fig.Fill(fig.WeaklyTyped(strict), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Port != 8080 || !v.Debug {
+			t.Fatal("not match: ", v)
+		}
+		v = server{}
+		if err := fig.FillExWithTagNames(strict, &v, false, []string{fig.TagPrefixName}, []string{fig.TagName}); err == nil {
+			t.Fatal("expect error but get ", v)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"bytes"
+	"github.com/BurntSushi/toml"
+	"io"
+)
+
+// toml只允许table作为顶层元素，序列化时使用该key包装实际的值
+const tomlValueKey = "value"
+
+type TomlReader struct{}
+
+func NewTomlReader() *TomlReader {
+	return &TomlReader{}
+}
+
+type TomlLoader struct{}
+
+func NewTomlLoader() *TomlLoader {
+	return &TomlLoader{}
+}
+
+func (v *TomlReader) Read(r io.Reader) (*Value, error) {
+	buf := bytes.NewBuffer(nil)
+
+	_, err := io.Copy(buf, r)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := Value{}
+	logf("value: %s\n", buf.String())
+	_, err = toml.Decode(buf.String(), &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeTomlValue(ret)
+	return &ret, nil
+}
+
+// 将array of tables（[]map[string]interface{}）转换为[]interface{}，与其他ValueReader保持一致
+func normalizeTomlValue(v map[string]interface{}) {
+	for k, o := range v {
+		v[k] = normalizeToml(o)
+	}
+}
+
+func normalizeToml(o interface{}) interface{} {
+	switch v := o.(type) {
+	case map[string]interface{}:
+		normalizeTomlValue(v)
+		return v
+	case []map[string]interface{}:
+		ret := make([]interface{}, len(v))
+		for i := range v {
+			normalizeTomlValue(v[i])
+			ret[i] = v[i]
+		}
+		return ret
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeToml(v[i])
+		}
+		return v
+	}
+	return o
+}
+
+func (v *TomlLoader) Serialize(o interface{}) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	err := toml.NewEncoder(buf).Encode(map[string]interface{}{
+		tomlValueKey: o,
+	})
+	return buf.String(), err
+}
+
+func (v *TomlLoader) Deserialize(value string, result interface{}) error {
+	ret := map[string]toml.Primitive{}
+	md, err := toml.Decode(value, &ret)
+	if err != nil {
+		return err
+	}
+	return md.PrimitiveDecode(ret[tomlValueKey], result)
+}
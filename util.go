@@ -6,12 +6,12 @@
 package fig
 
 import (
-	"errors"
 	"fmt"
-	"github.com/xfali/reflection"
+	"net"
+	"net/url"
 	"os"
-	"reflect"
 	"strings"
+	"time"
 )
 
 const (
@@ -109,6 +109,144 @@ func GetFloat64(props Properties) func(key string, defaultValue float64) float64
 	}
 }
 
+func GetDuration(props Properties) func(key string, defaultValue time.Duration) time.Duration {
+	return func(key string, defaultValue time.Duration) time.Duration {
+		var v time.Duration
+		err := props.GetValue(key, &v)
+		if err != nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+func GetByteSize(props Properties) func(key string, defaultValue ByteSize) ByteSize {
+	return func(key string, defaultValue ByteSize) ByteSize {
+		var v ByteSize
+		err := props.GetValue(key, &v)
+		if err != nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+// param: props 属性
+// param: layouts 时间格式，依次尝试，为空时使用time.RFC3339
+func GetTime(props Properties, layouts ...string) func(key string, defaultValue time.Time) time.Time {
+	return func(key string, defaultValue time.Time) time.Time {
+		if len(layouts) == 0 {
+			var v time.Time
+			err := props.GetValue(key, &v)
+			if err != nil {
+				return defaultValue
+			} else {
+				return v
+			}
+		}
+		if !props.Has(key) {
+			return defaultValue
+		}
+		s := strings.TrimSpace(props.Get(key, ""))
+		for _, layout := range layouts {
+			if v, err := time.Parse(layout, s); err == nil {
+				return v
+			}
+		}
+		return defaultValue
+	}
+}
+
+func GetURL(props Properties) func(key string, defaultValue *url.URL) *url.URL {
+	return func(key string, defaultValue *url.URL) *url.URL {
+		var v *url.URL
+		err := props.GetValue(key, &v)
+		if err != nil || v == nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+func GetIP(props Properties) func(key string, defaultValue net.IP) net.IP {
+	return func(key string, defaultValue net.IP) net.IP {
+		var v net.IP
+		err := props.GetValue(key, &v)
+		if err != nil || v == nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+func GetCIDR(props Properties) func(key string, defaultValue *net.IPNet) *net.IPNet {
+	return func(key string, defaultValue *net.IPNet) *net.IPNet {
+		var v *net.IPNet
+		err := props.GetValue(key, &v)
+		if err != nil || v == nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+// 属性值可以是列表或逗号分隔的字符串，如"a,b,c"
+func GetStringSlice(props Properties) func(key string, defaultValue []string) []string {
+	return func(key string, defaultValue []string) []string {
+		var v []string
+		err := getSplitValue(props, key, DefaultSeparator, &v)
+		if err != nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+// 属性值可以是列表或逗号分隔的字符串，如"1,2,3"
+func GetIntSlice(props Properties) func(key string, defaultValue []int) []int {
+	return func(key string, defaultValue []int) []int {
+		var v []int
+		err := getSplitValue(props, key, DefaultSeparator, &v)
+		if err != nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+// 属性值可以是map或逗号分隔的字符串，如"a=1,b=2"，字符串时值均为string
+func GetStringMap(props Properties) func(key string, defaultValue map[string]interface{}) map[string]interface{} {
+	return func(key string, defaultValue map[string]interface{}) map[string]interface{} {
+		var v map[string]interface{}
+		err := getSplitValue(props, key, DefaultSeparator, &v)
+		if err != nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
+// 属性值可以是map或逗号分隔的字符串，如"a=1,b=2"
+func GetStringMapString(props Properties) func(key string, defaultValue map[string]string) map[string]string {
+	return func(key string, defaultValue map[string]string) map[string]string {
+		var v map[string]string
+		err := getSplitValue(props, key, DefaultSeparator, &v)
+		if err != nil {
+			return defaultValue
+		} else {
+			return v
+		}
+	}
+}
+
 func LoadFile(filename string, reader ValueReader, loader ValueLoader) (Properties, error) {
 	f, err := os.Open(filename)
 	if err != nil {
@@ -119,6 +257,7 @@ func LoadFile(filename string, reader ValueReader, loader ValueLoader) (Properti
 	prop := New()
 	prop.SetValueReader(reader)
 	prop.SetValueLoader(loader)
+	prop.sourceFile = filename
 	err = prop.ReadValue(f)
 	return prop, err
 }
@@ -131,17 +270,49 @@ func LoadYamlFile(filename string) (Properties, error) {
 	return LoadFile(filename, NewYamlReader(), NewYamlLoader())
 }
 
+func LoadTomlFile(filename string) (Properties, error) {
+	return LoadFile(filename, NewTomlReader(), NewTomlLoader())
+}
+
+// .properties文件的值均为字符串，返回的属性使用弱类型转换，如GetInt可以读取"8080"
+func LoadPropertiesFile(filename string) (Properties, error) {
+	return loadWeakFile(filename, NewPropertiesReader())
+}
+
+// ini文件的值均为字符串，返回的属性使用弱类型转换
+func LoadIniFile(filename string) (Properties, error) {
+	return loadWeakFile(filename, NewIniReader())
+}
+
+func loadWeakFile(filename string, reader ValueReader) (Properties, error) {
+	prop, err := LoadFile(filename, reader, NewYamlLoader())
+	if err != nil {
+		return prop, err
+	}
+	prop.(*DefaultProperties).SetWeaklyTyped(true)
+	return prop, nil
+}
+
+// 属性不存在的field保持不变，转换失败的field保持不变并返回错误
 // param: prop 属性
 // param: result 填充的struct
-// result: result如果不为struct的指针返回错误，填充时异常返回错误
+// result: result如果不为struct的指针返回错误，填充失败时返回Errors，每个错误为*FieldError
 func Fill(prop Properties, result interface{}) error {
 	return FillEx(prop, result, false)
 }
 
+// 与Fill相同，但属性不存在（且没有default）也作为错误返回
+// param: prop 属性
+// param: result 填充的struct
+// result: result如果不为struct的指针返回错误，填充失败或属性不存在时返回Errors，每个错误为*FieldError
+func FillStrict(prop Properties, result interface{}) error {
+	return fill(prop, result, false, []string{TagPrefixName}, []string{TagName}, true)
+}
+
 // param: prop 属性
 // param: result 填充的struct
 // param: withField 是否根据field name填充
-// result: result如果不为struct的指针返回错误，填充时异常返回错误
+// result: result如果不为struct的指针返回错误，填充失败时返回Errors
 func FillEx(prop Properties, result interface{}, withField bool) error {
 	return FillExWithTagName(prop, result, withField, TagPrefixName, TagName)
 }
@@ -151,140 +322,42 @@ func FillEx(prop Properties, result interface{}, withField bool) error {
 // param: withField 是否根据field name填充
 // param: tagPxName tag前缀名，后续都使用tagPxName定义的名称做前缀
 // param: tagName tag名
-// result: result如果不为struct的指针返回错误，填充时异常返回错误
+// result: result如果不为struct的指针返回错误，填充失败时返回Errors
 func FillExWithTagName(prop Properties, result interface{}, withField bool, tagPxName, tagName string) error {
-	t := reflect.TypeOf(result)
-	v := reflect.ValueOf(result)
-
-	if t.Kind() != reflect.Ptr {
-		return errors.New("result must be ptr")
-	}
-	t = t.Elem()
-	v = v.Elem()
-
-	if t.Kind() != reflect.Struct {
-		return errors.New("result must be struct ptr")
-	}
-
-	prefix := ""
-	for i := 0; i < v.NumField(); i++ {
-		field := t.Field(i)
-		tag := field.Tag.Get(tagPxName)
-		if tag != "" {
-			prefix = tag
-			continue
-		}
-		tag = field.Tag.Get(tagName)
-		if tag != "" {
-			if tag == "-" {
-				continue
-			}
-		} else if withField {
-			tag = field.Name
-		}
-
-		if tag != "" {
-			if prefix != "" {
-				tag = prefix + "." + tag
-			}
-			c := reflect.New(field.Type).Interface()
-			err := prop.GetValue(tag, c)
-			if err != nil {
-				logf(err.Error())
-			}
-			fieldValue := v.Field(i)
-			if fieldValue.CanSet() {
-				fieldValue.Set(reflect.ValueOf(c).Elem())
-			}
-		}
-	}
-	return nil
+	return fill(prop, result, withField, []string{tagPxName}, []string{tagName}, false)
 }
 
+// 属性不存在也作为错误返回
 // param: prop 属性
 // param: result 填充的struct
 // param: withField 是否根据field name填充
 // param: tagPxNames tag前缀名，后续都使用tagPxName定义的名称做前缀
 // param: tagNames tag名
-// result: result如果不为struct的指针返回错误，填充时异常返回错误
+// result: result如果不为struct的指针返回错误，填充失败或属性不存在时返回Errors
 func FillExWithTagNames(prop Properties, result interface{}, withField bool, tagPxNames, tagNames []string) error {
 	if len(tagPxNames) != len(tagNames) {
 		return fmt.Errorf("tagPxNames lens not the same with tagNames")
 	}
-	t := reflect.TypeOf(result)
-	v := reflect.ValueOf(result)
+	return fill(prop, result, withField, tagPxNames, tagNames, true)
+}
 
-	if t.Kind() != reflect.Ptr {
-		return errors.New("result must be ptr")
+func fill(prop Properties, result interface{}, withField bool, tagPxNames, tagNames []string, strict bool) error {
+	v, err := structValue(result)
+	if err != nil {
+		return err
 	}
-	t = t.Elem()
-	v = v.Elem()
-
-	if t.Kind() != reflect.Struct {
-		return errors.New("result must be struct ptr")
+	f := &filler{
+		prop:       prop,
+		withField:  withField,
+		tagPxNames: tagPxNames,
+		tagNames:   tagNames,
+		strict:     strict,
 	}
-
-	errs := Errors{}
-	prefix := make([]string, len(tagPxNames))
-	for i := 0; i < v.NumField(); i++ {
-		field := t.Field(i)
-		for tagIndex := range tagPxNames {
-			tagPxName := tagPxNames[tagIndex]
-			tagName := tagNames[tagIndex]
-			tagValue := field.Tag.Get(tagPxName)
-			if tagValue != "" {
-				prefix[tagIndex] = tagValue
-				continue
-			}
-			tagValue = field.Tag.Get(tagName)
-			if tagValue != "" {
-				if tagValue == "-" {
-					break
-				}
-			} else if tagIndex < len(tagPxNames)-1 {
-				continue
-			} else if withField {
-				tagValue = field.Name
-			}
-
-			if tagValue != "" {
-				tags := strings.Split(tagValue, ",")
-				defaultStr := ""
-				if len(tags) > 1 {
-					tagValue = tags[0]
-					if index := strings.Index(tags[1], "default="); index != -1 {
-						defaultStr = tags[1][len("default="):]
-					}
-				}
-				if prefix[tagIndex] != "" {
-					tagValue = prefix[tagIndex] + "." + tagValue
-				}
-				c := reflect.New(field.Type).Interface()
-				fieldValue := v.Field(i)
-				if defaultStr == "" {
-					err := prop.GetValue(tagValue, c)
-					if err != nil {
-						logf(err.Error())
-						errs.AddError(err)
-						break
-					}
-					if fieldValue.CanSet() {
-						fieldValue.Set(reflect.ValueOf(c).Elem())
-					}
-				} else {
-					value := prop.Get(tagValue, defaultStr)
-					if ok := reflection.SetValue(fieldValue, reflect.ValueOf(value)); !ok {
-						errs.AddError(errors.New("Not assigned. "))
-					}
-				}
-				break
-			}
-		}
-	}
-	if errs.Empty() {
+	f.fillObject("", "", v)
+	if f.errs.Empty() {
 		return nil
 	}
-	return errs
+	return f.errs
 }
 
 type Errors []error
@@ -298,6 +371,11 @@ func (es *Errors) AddError(e error) *Errors {
 	return es
 }
 
+// 支持使用errors.Is/errors.As判断其中的错误，需要Go 1.20及以上的标准库，更早的版本可以直接遍历Errors
+func (es Errors) Unwrap() []error {
+	return es
+}
+
 func (es Errors) Error() string {
 	buf := strings.Builder{}
 	for i := range es {
@@ -315,7 +393,7 @@ func GetEnvs() map[string]string {
 	for _, env := range s {
 		env := strings.TrimSpace(env)
 		if env != "" {
-			pair := strings.Split(env, "=")
+			pair := strings.SplitN(env, "=", 2)
 			if len(pair) == 2 {
 				ret[pair[0]] = pair[1]
 			}
@@ -5,7 +5,11 @@
 
 package fig
 
-import "io"
+import (
+	"errors"
+	"io"
+	"sort"
+)
 
 func MergeProperties(props ...Properties) Properties {
 	return &mergedProperties{
@@ -34,34 +38,164 @@ func (p *mergedProperties) SetValueLoader(l ValueLoader) {
 
 // param: key属性名称
 // param: defaultValue: 默认值
-// return: 属性值，如不存在返回默认值
+// return: 属性值，使用第一个存在该key的属性，均不存在时返回默认值
 func (p *mergedProperties) Get(key string, defaultValue string) string {
-	l := len(p.props)
-	if l > 0 {
-		for i := 0; i < l-1; i++ {
-			value := p.props[i].Get(key, "")
-			if value != "" {
-				return value
-			}
+	for i := range p.props {
+		if p.props[i].Has(key) {
+			return p.props[i].Get(key, defaultValue)
 		}
-		return p.props[l-1].Get(key, defaultValue)
 	}
 	return defaultValue
 }
 
 // param: key属性名称
 // param: result: 填充对象指针
-// return: 正常返回nil,否则返回错误
-func (p *mergedProperties) GetValue(key string, result interface{}) (err error) {
+// return: 正常返回nil,否则返回错误，仅当key不存在（ErrKeyNotFound）时使用下一个属性
+func (p *mergedProperties) GetValue(key string, result interface{}) error {
+	return p.getValue(key, result, false)
+}
+
+func (p *mergedProperties) getValue(key string, result interface{}, weak bool) (err error) {
+	err = keyNotFound(key)
 	for i := range p.props {
-		err = p.props[i].GetValue(key, result)
-		if err == nil {
-			return nil
+		if weak {
+			err = getWeakValue(p.props[i], key, result)
+		} else {
+			err = p.props[i].GetValue(key, result)
+		}
+		if !errors.Is(err, ErrKeyNotFound) {
+			return err
 		}
 	}
 	return
 }
 
+// 深度合并props的属性值，与MergeProperties一致props中靠前的优先
+// 两者均为map的节点逐层合并，列表按strategy合并，其他节点使用优先级高的值
+// param: strategy 列表的合并策略
+// return: 包含合并结果的属性，为调用时的快照，之后props的变化不会影响该属性；
+// 任一props使用弱类型转换时结果也使用弱类型转换，DecodeHook按props的优先级保留
+func DeepMergeProperties(strategy ListMergeStrategy, props ...Properties) (*DefaultProperties, error) {
+	ret := New()
+	merged := Value{}
+	prov := provenanceMap{}
+	for i := len(props) - 1; i >= 0; i-- {
+		v, err := propertiesValue(props[i], strategy)
+		if err != nil {
+			return nil, err
+		}
+		m := &valueMerger{
+			strategy: strategy,
+			dstProv:  prov,
+			srcProv:  explainAll(props[i]),
+		}
+		m.mergeValue(merged, v, "")
+		if p := defaultProperties(props[i]); p != nil {
+			ret.SetValueLoader(p.loader)
+			ret.weaklyTyped = ret.weaklyTyped || p.weaklyTyped
+		}
+		if _, ok := props[i].(*weakProperties); ok {
+			ret.weaklyTyped = true
+		}
+		// 优先级高的props在后面合并，其DecodeHook放在前面
+		ret.hooks = append(append([]decodeHook{}, getDecodeHooks(props[i])...), ret.hooks...)
+	}
+	ret.Env = GetEnvs()
+	ret.publish(&merged, prov)
+	return ret, nil
+}
+
+func explainAll(prop Properties) provenanceMap {
+	ret := provenanceMap{}
+	for _, v := range prop.Explain("") {
+		p := v
+		ret[p.Key] = &p
+	}
+	return ret
+}
+
+func defaultProperties(prop Properties) *DefaultProperties {
+	switch p := prop.(type) {
+	case *DefaultProperties:
+		return p
+	case *SettableProperties:
+		return &p.DefaultProperties
+	}
+	return nil
+}
+
+// 获得属性值的副本
+func propertiesValue(prop Properties, strategy ListMergeStrategy) (map[string]interface{}, error) {
+	if p := defaultProperties(prop); p != nil {
+		s := p.load()
+		if s.value == nil {
+			return map[string]interface{}{}, nil
+		}
+		return copyValue(map[string]interface{}(*s.value)).(map[string]interface{}), nil
+	}
+
+	if p, ok := prop.(*mergedProperties); ok {
+		m, err := DeepMergeProperties(strategy, p.props...)
+		if err != nil {
+			return nil, err
+		}
+		return *m.Value, nil
+	}
+
+	return prop.AllSettings(), nil
+}
+
+// param: key属性名称，为空时返回全部
+// return: key及其子节点的属性值来源，靠前的属性生效，靠后的属性中相同key的来源作为覆盖链
+func (p *mergedProperties) Explain(key string) []Provenance {
+	ret := provenanceMap{}
+	for i := len(p.props) - 1; i >= 0; i-- {
+		for _, v := range p.props[i].Explain(key) {
+			ret.take(provenanceMap{v.Key: &v}, v.Key, v.Key)
+		}
+	}
+	return ret.explain(key)
+}
+
+func (p *mergedProperties) Has(key string) bool {
+	for i := range p.props {
+		if p.props[i].Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// return: 所有属性中该节点的子节点的并集
+func (p *mergedProperties) Keys(prefix string) []string {
+	var ret []string
+	exist := map[string]bool{}
+	for i := range p.props {
+		for _, k := range p.props[i].Keys(prefix) {
+			if !exist[k] {
+				exist[k] = true
+				ret = append(ret, k)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (p *mergedProperties) Sub(prefix string) Properties {
+	return newSubProperties(p, prefix)
+}
+
+// return: 按ListReplace深度合并的属性值
+func (p *mergedProperties) AllSettings() map[string]interface{} {
+	m, err := DeepMergeProperties(ListReplace, p.props...)
+	if err != nil {
+		logf("merge properties failed: %s\n", err.Error())
+		return map[string]interface{}{}
+	}
+	return m.AllSettings()
+}
+
 type SettableProperties struct {
 	DefaultProperties
 }
@@ -70,22 +204,44 @@ func NewSettableProperties(opts ...Opt) *SettableProperties {
 	ret := &SettableProperties{
 		DefaultProperties: *New(opts...),
 	}
-	ret.Value = &Value{}
+	ret.publish(&Value{}, nil)
 	return ret
 }
 
 func (p *SettableProperties) Set(key string, value interface{}) error {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-
-	(*p.Value)[key] = value
-
+	p.update(key, func(v Value) {
+		v[key] = value
+	})
 	return nil
 }
 
 func (p *SettableProperties) Delete(key string) {
+	p.update(key, func(v Value) {
+		delete(v, key)
+	})
+}
+
+// 复制当前的属性值及来源，修改后发布新的快照
+func (p *SettableProperties) update(key string, f func(v Value)) {
 	p.lock.Lock()
-	defer p.lock.Unlock()
+	cur := p.load()
+	var old *Value
+	v := Value{}
+	if cur.value != nil {
+		old = cur.value
+		for k, o := range *cur.value {
+			v[k] = o
+		}
+	}
+	f(v)
+	prov := cur.provenance.copy()
+	if o, ok := v[key]; ok {
+		prov.replace(joinKey("", key), o, Source{Name: sourceSet})
+	} else {
+		prov.remove(joinKey("", key))
+	}
+	p.publish(&v, prov)
+	p.lock.Unlock()
 
-	delete(*p.Value, key)
+	p.notifyChanges(old, &v)
 }
@@ -0,0 +1,285 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// struct实现该接口时，Fill在该struct（包括嵌套的struct及slice、map的元素）填充成功后调用Validate，用于跨field的校验
+type Validator interface {
+	Validate() error
+}
+
+// 校验失败，Fill将所有校验失败与填充失败一起以Errors返回
+type ValidationError struct {
+	// field路径，Validator校验失败时为struct的路径
+	Field string
+	// 属性的完整key
+	Key string
+	// 失败的规则，如"min=1"，Validator校验失败时为"Validate"
+	Rule string
+	// field的值，secret时不显示
+	Value interface{}
+	// Validator返回的错误
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("field: %s key: %s %s: %v", e.Field, e.Key, e.Rule, e.Err)
+	}
+	return fmt.Sprintf("field: %s key: %s violates %s: %v", e.Field, e.Key, e.Rule, e.Value)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// tag中的校验规则
+type rule struct {
+	name string
+	arg  string
+	re   *regexp.Regexp
+}
+
+func newRule(name, arg string) (rule, error) {
+	r := rule{name: name, arg: arg}
+	if name == "regex" {
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return r, fmt.Errorf("%w: regex %v", ErrInvalidTag, err)
+		}
+		r.re = re
+	}
+	return r, nil
+}
+
+func (r rule) String() string {
+	if r.arg == "" {
+		return r.name
+	}
+	return r.name + "=" + r.arg
+}
+
+// return: 是否满足规则，规则不适用于该类型或参数错误时返回ErrInvalidTag
+func (r rule) check(v reflect.Value) (bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			// 空指针只有nonempty不满足
+			return r.name != "nonempty", nil
+		}
+		v = v.Elem()
+	}
+	switch r.name {
+	case "min", "max":
+		c, err := compare(v, r.arg)
+		if err != nil {
+			return false, r.invalid(v, err)
+		}
+		if r.name == "min" {
+			return c >= 0, nil
+		}
+		return c <= 0, nil
+	case "oneof":
+		s, ok := oneofString(v)
+		if !ok {
+			return false, r.invalid(v, nil)
+		}
+		for _, o := range strings.Fields(r.arg) {
+			if s == o {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "regex":
+		if v.Kind() != reflect.String {
+			return false, r.invalid(v, nil)
+		}
+		return r.re.MatchString(v.String()), nil
+	case "nonempty":
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			return v.Len() > 0, nil
+		}
+		return !v.IsZero(), nil
+	case "url":
+		var u *url.URL
+		switch {
+		case v.Type() == urlType:
+			o := v.Interface().(url.URL)
+			u = &o
+		case v.Kind() == reflect.String:
+			var err error
+			if u, err = url.Parse(v.String()); err != nil {
+				return false, nil
+			}
+		default:
+			return false, r.invalid(v, nil)
+		}
+		return u.Scheme != "" && u.Host != "", nil
+	}
+	return false, r.invalid(v, nil)
+}
+
+func (r rule) invalid(v reflect.Value, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidTag, r, err)
+	}
+	return fmt.Errorf("%w: %s not applicable to %v", ErrInvalidTag, r, v.Type())
+}
+
+// 比较v与arg，数字比较大小，string比较字符数，slice、map比较长度
+// time.Duration、ByteSize的arg可以使用"1s"、"1MiB"等格式
+// return: v小于、等于、大于arg时分别返回-1、0、1
+func compare(v reflect.Value, arg string) (int, error) {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt(v.Int(), int64(d)), nil
+	case v.Type() == byteSizeType:
+		b, err := ParseByteSize(arg)
+		if err != nil {
+			return 0, err
+		}
+		return compareUint(v.Uint(), uint64(b)), nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			return compareInt(v.Int(), i), nil
+		}
+		return compareNumber(float64(v.Int()), arg)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i, err := strconv.ParseUint(arg, 10, 64); err == nil {
+			return compareUint(v.Uint(), i), nil
+		}
+		return compareNumber(float64(v.Uint()), arg)
+	case reflect.Float32, reflect.Float64:
+		return compareNumber(v.Float(), arg)
+	case reflect.String:
+		return compareLen(utf8.RuneCountInString(v.String()), arg)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return compareLen(v.Len(), arg)
+	}
+	return 0, fmt.Errorf("not applicable to %v", v.Type())
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareNumber(a float64, arg string) (int, error) {
+	b, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(b) {
+		return 0, fmt.Errorf("invalid number %q", arg)
+	}
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func compareLen(n int, arg string) (int, error) {
+	l, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid length %q", arg)
+	}
+	return compareInt(int64(n), int64(l)), nil
+}
+
+// oneof比较使用的字符串
+func oneofString(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), true
+	}
+	return "", false
+}
+
+// 使用tag中的规则校验field，所有不满足的规则均记录错误
+func (f *filler) validateField(path, key string, ft fieldTag, fv reflect.Value) {
+	for _, r := range ft.rules {
+		ok, err := r.check(fv)
+		if err != nil {
+			f.addError(path, key, ft, err)
+			continue
+		}
+		if ok {
+			continue
+		}
+		var value interface{} = secretMask
+		if !ft.secret && fv.CanInterface() {
+			value = fv.Interface()
+		}
+		err = &ValidationError{
+			Field: path,
+			Key:   key,
+			Rule:  r.String(),
+			Value: value,
+		}
+		// 错误由Fill返回，不再输出日志
+		f.errs.AddError(err)
+	}
+}
+
+// 调用struct实现的Validator
+func (f *filler) validateStruct(path, key string, v reflect.Value) {
+	if !v.CanAddr() || !v.Addr().Type().Implements(validatorType) || !v.Addr().CanInterface() {
+		return
+	}
+	err := v.Addr().Interface().(Validator).Validate()
+	if err == nil {
+		return
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		err = &ValidationError{
+			Field: path,
+			Key:   key,
+			Rule:  "Validate",
+			Err:   err,
+		}
+	}
+	f.errs.AddError(err)
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"fmt"
+	"strings"
+)
+
+func joinKey(prefix, key string) string {
+	return joinPath(prefix, formatKey([]keySegment{{Key: key, Index: -1}}))
+}
+
+// 根据key（语法见parseKey）查找Value中的值，key为空时返回v本身
+func lookupValue(v interface{}, key string) (interface{}, bool) {
+	if key == "" {
+		return v, v != nil
+	}
+	segs, err := parseKey(key)
+	if err != nil {
+		return nil, false
+	}
+	return lookupPath(v, segs)
+}
+
+// 按路径设置Value中的值，路径中不存在的节点自动创建
+// 路径中间节点已存在且不为map时返回错误
+func setValue(v map[string]interface{}, path []string, value interface{}) error {
+	for i, k := range path[:len(path)-1] {
+		o, ok := v[k]
+		if !ok {
+			m := map[string]interface{}{}
+			v[k] = m
+			v = m
+			continue
+		}
+		m, ok := o.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("key: %s conflict, %s is not a map", strings.Join(path, "."), strings.Join(path[:i+1], "."))
+		}
+		v = m
+	}
+	k := path[len(path)-1]
+	if o, ok := v[k]; ok {
+		if _, ok := o.(map[string]interface{}); ok {
+			if _, ok := value.(map[string]interface{}); !ok {
+				return fmt.Errorf("key: %s conflict, it is a map", strings.Join(path, "."))
+			}
+		}
+	}
+	v[k] = value
+	return nil
+}
+
+// 列表的合并策略
+type ListMergeStrategy int
+
+const (
+	// 使用优先级高的列表替换
+	ListReplace ListMergeStrategy = iota
+	// 将优先级高的列表追加到优先级低的列表之后
+	ListAppend
+	// 按下标合并，同一下标均为map时深度合并，否则使用优先级高的元素替换
+	ListMergeByIndex
+)
+
+// 将src深度合并到dst中（src优先），两者均为map的节点逐层合并，列表按strategy合并，其他节点使用src中的值替换
+// src中的节点会被dst引用，如需保持src不变应先使用copyValue复制
+func mergeValue(dst, src map[string]interface{}, strategy ListMergeStrategy) {
+	(&valueMerger{strategy: strategy}).mergeValue(dst, src, "")
+}
+
+// 合并属性值，dstProv不为nil时同时将srcProv中的来源合并到dstProv
+type valueMerger struct {
+	strategy ListMergeStrategy
+	dstProv  provenanceMap
+	srcProv  provenanceMap
+}
+
+func (t *valueMerger) mergeValue(dst, src map[string]interface{}, prefix string) {
+	for k, sv := range src {
+		key := joinKey(prefix, k)
+		if dv, ok := dst[k]; ok {
+			dst[k] = t.mergeNode(dv, sv, key)
+		} else {
+			dst[k] = sv
+			t.dstProv.take(t.srcProv, key, key)
+		}
+	}
+}
+
+func (t *valueMerger) mergeNode(dst, src interface{}, key string) interface{} {
+	switch d := dst.(type) {
+	case map[string]interface{}:
+		if s, ok := src.(map[string]interface{}); ok {
+			t.mergeValue(d, s, key)
+			return d
+		}
+	case []interface{}:
+		if s, ok := src.([]interface{}); ok {
+			switch t.strategy {
+			case ListAppend:
+				for i := range s {
+					t.dstProv.take(t.srcProv, indexKey(key, i), indexKey(key, len(d)+i))
+				}
+				return append(d, s...)
+			case ListMergeByIndex:
+				for i := range s {
+					if i < len(d) {
+						d[i] = t.mergeNode(d[i], s[i], indexKey(key, i))
+					} else {
+						d = append(d, s[i])
+						t.dstProv.take(t.srcProv, indexKey(key, i), indexKey(key, i))
+					}
+				}
+				return d
+			}
+		}
+	}
+
+	if t.dstProv != nil {
+		// 替换整个节点：原有的来源仅保留与新叶子节点key相同的，作为覆盖链
+		old := provenanceMap{}
+		old.take(t.dstProv, key, key)
+		t.dstProv.remove(key)
+		for k, p := range old {
+			if _, ok := t.srcProv[k]; ok {
+				t.dstProv[k] = p
+			}
+		}
+		t.dstProv.take(t.srcProv, key, key)
+	}
+	return src
+}
+
+// 深度复制Value中的map及列表
+func copyValue(v interface{}) interface{} {
+	switch o := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(o))
+		for k, v := range o {
+			ret[k] = copyValue(v)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(o))
+		for i := range o {
+			ret[i] = copyValue(o[i])
+		}
+		return ret
+	}
+	return v
+}
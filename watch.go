@@ -0,0 +1,178 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWatchInterval = 3 * time.Second
+)
+
+type WatchOpt func(w *FileWatcher)
+
+// FileWatcher 记录配置文件路径，定时检查文件的内容hash，文件变化时重新加载配置。
+// 修改时间精度较低的文件系统中，大小不变的修改可能不改变修改时间，因此每次检查都计算hash。
+type FileWatcher struct {
+	prop     *DefaultProperties
+	filename string
+	interval time.Duration
+	onError  func(err error)
+
+	// 是否已记录文件的hash
+	loaded bool
+	hash   [sha256.Size]byte
+
+	lock     sync.Mutex
+	stopChan chan struct{}
+	wait     sync.WaitGroup
+}
+
+// 配置文件检查间隔
+func WatchInterval(interval time.Duration) WatchOpt {
+	return func(w *FileWatcher) {
+		w.interval = interval
+	}
+}
+
+// 配置重新加载失败时的处理方法，默认输出日志
+func WatchErrorHandler(handler func(err error)) WatchOpt {
+	return func(w *FileWatcher) {
+		w.onError = handler
+	}
+}
+
+// param: prop 需要重新加载的属性
+// param: filename 配置文件路径
+// return: 文件监听器，需调用Start开始监听
+func NewFileWatcher(prop *DefaultProperties, filename string, opts ...WatchOpt) *FileWatcher {
+	ret := &FileWatcher{
+		prop:     prop,
+		filename: filename,
+		interval: DefaultWatchInterval,
+		onError: func(err error) {
+			logf("reload %s failed: %s\n", filename, err.Error())
+		},
+	}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return ret
+}
+
+// 加载配置文件并开始监听文件变化
+func WatchFile(filename string, reader ValueReader, loader ValueLoader, opts ...WatchOpt) (*FileWatcher, error) {
+	prop := New()
+	prop.SetValueReader(reader)
+	prop.SetValueLoader(loader)
+	prop.sourceFile = filename
+
+	w := NewFileWatcher(prop, filename, opts...)
+	if _, err := w.Reload(); err != nil {
+		return nil, err
+	}
+	w.Start()
+	return w, nil
+}
+
+func WatchJsonFile(filename string, opts ...WatchOpt) (*FileWatcher, error) {
+	return WatchFile(filename, NewJsonReader(), NewJsonLoader(), opts...)
+}
+
+func WatchYamlFile(filename string, opts ...WatchOpt) (*FileWatcher, error) {
+	return WatchFile(filename, NewYamlReader(), NewYamlLoader(), opts...)
+}
+
+func WatchTomlFile(filename string, opts ...WatchOpt) (*FileWatcher, error) {
+	return WatchFile(filename, NewTomlReader(), NewTomlLoader(), opts...)
+}
+
+// 获得监听的属性
+func (w *FileWatcher) Properties() *DefaultProperties {
+	return w.prop
+}
+
+// 获得监听的文件路径
+func (w *FileWatcher) Filename() string {
+	return w.filename
+}
+
+// 检查文件是否变化，如变化则重新加载
+// return: 是否重新加载，重新加载失败时返回错误且原配置保持不变
+func (w *FileWatcher) Reload() (bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	data, err := ioutil.ReadFile(w.filename)
+	if err != nil {
+		return false, err
+	}
+	hash := sha256.Sum256(data)
+	unchanged := hash == w.hash && w.loaded
+
+	// 无论加载是否成功都记录文件状态，避免同一份错误的文件被反复加载
+	w.loaded = true
+	w.hash = hash
+	if unchanged {
+		return false, nil
+	}
+
+	err = w.prop.ReadValue(bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// 开始监听，重复调用无效
+func (w *FileWatcher) Start() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stopChan != nil {
+		return
+	}
+	w.stopChan = make(chan struct{})
+	w.wait.Add(1)
+	go w.loop(w.stopChan)
+}
+
+// 停止监听
+func (w *FileWatcher) Close() error {
+	w.lock.Lock()
+	stopChan := w.stopChan
+	w.stopChan = nil
+	w.lock.Unlock()
+
+	if stopChan != nil {
+		close(stopChan)
+		w.wait.Wait()
+	}
+	return nil
+}
+
+func (w *FileWatcher) loop(stopChan chan struct{}) {
+	defer w.wait.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			if _, err := w.Reload(); err != nil && w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package fig
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// 弱类型转换支持的bool字符串，忽略大小写
+var weakBools = map[string]bool{
+	"1":     true,
+	"t":     true,
+	"true":  true,
+	"y":     true,
+	"yes":   true,
+	"on":    true,
+	"0":     false,
+	"f":     false,
+	"false": false,
+	"n":     false,
+	"no":    false,
+	"off":   false,
+}
+
+var (
+	errInvalidBool = errors.New("invalid bool")
+	errNotNumber   = errors.New("not a number")
+)
+
+// 开启弱类型转换，GetValue及使用该属性的Fill支持：
+// string与number、bool互相转换（bool支持"1"、"yes"、"on"等），单个值转换为只有一个元素的slice
+func SetWeaklyTyped(weak bool) Opt {
+	return func(ctx *DefaultProperties) error {
+		ctx.SetWeaklyTyped(weak)
+		return nil
+	}
+}
+
+func (ctx *DefaultProperties) SetWeaklyTyped(weak bool) {
+	ctx.weaklyTyped = weak
+}
+
+// 返回使用弱类型转换的属性，不影响props本身，可用于单次Fill：
+// fig.Fill(fig.WeaklyTyped(config), &v)
+// param: props 属性
+// return: GetValue使用弱类型转换的属性
+func WeaklyTyped(props Properties) Properties {
+	if props == nil {
+		return nil
+	}
+	if p, ok := props.(*weakProperties); ok {
+		return p
+	}
+	return &weakProperties{props}
+}
+
+// 可以指定是否使用弱类型转换的属性
+type weakValueGetter interface {
+	getValue(key string, result interface{}, weak bool) error
+}
+
+type weakProperties struct {
+	Properties
+}
+
+func (p *weakProperties) GetValue(key string, result interface{}) error {
+	return getWeakValue(p.Properties, key, result)
+}
+
+func (p *weakProperties) getValue(key string, result interface{}, weak bool) error {
+	return getWeakValue(p.Properties, key, result)
+}
+
+func (p *weakProperties) Sub(prefix string) Properties {
+	return newSubProperties(p, prefix)
+}
+
+// 使用弱类型转换获得属性值
+func getWeakValue(props Properties, key string, result interface{}) error {
+	if w, ok := props.(weakValueGetter); ok {
+		return w.getValue(key, result, true)
+	}
+	err := props.GetValue(key, result)
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		return err
+	}
+	var raw interface{}
+	if err := props.GetValue(key, &raw); err != nil {
+		return err
+	}
+	d := valueDecoder{scalarToString: true, weaklyTyped: true}
+	err = d.decode(raw, result)
+	if errors.As(err, &de) {
+		de.Key = joinPath(key, de.Key)
+	}
+	return err
+}
+
+// 弱类型转换不区分loader的格式
+type weakDecoder struct{}
+
+func (weakDecoder) Decode(o interface{}, result interface{}) error {
+	return weakDecoder{}.decodeHooks(o, result, nil)
+}
+
+func (weakDecoder) decodeHooks(o interface{}, result interface{}, hooks []decodeHook) error {
+	d := valueDecoder{scalarToString: true, weaklyTyped: true, hooks: hooks}
+	return d.decode(o, result)
+}
+
+// 类型不匹配时的弱类型转换
+// return: 是否已处理，已处理时返回转换的错误
+func (d *valueDecoder) decodeWeak(key string, v interface{}, dst reflect.Value) (bool, error) {
+	switch dst.Kind() {
+	case reflect.Bool:
+		if s, ok := v.(string); ok {
+			b, ok := weakBools[strings.ToLower(strings.TrimSpace(s))]
+			if !ok {
+				return true, decodeError(key, v, dst.Type(), errInvalidBool)
+			}
+			dst.SetBool(b)
+			return true, nil
+		}
+		if f, ok := number(v); ok {
+			dst.SetBool(f != 0)
+			return true, nil
+		}
+	case reflect.String:
+		if s, ok := scalarString(v); ok {
+			dst.SetString(s)
+			return true, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		switch o := v.(type) {
+		case string:
+			n := strings.TrimSpace(o)
+			if !isJsonNumber(n) {
+				return true, decodeError(key, v, dst.Type(), errNotNumber)
+			}
+			err := d.decodeValue(key, json.Number(n), dst)
+			var de *DecodeError
+			if errors.As(err, &de) {
+				de.Raw = v
+			}
+			return true, err
+		case bool:
+			n := json.Number("0")
+			if o {
+				n = "1"
+			}
+			return true, d.decodeValue(key, n, dst)
+		}
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if _, ok := listValue(v); ok {
+			break
+		}
+		if _, ok := number(v); ok || isScalar(v) {
+			s := reflect.MakeSlice(dst.Type(), 1, 1)
+			if err := d.decodeValue(indexKey(key, 0), v, s.Index(0)); err != nil {
+				return true, err
+			}
+			dst.Set(s)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool:
+		return true
+	}
+	return false
+}
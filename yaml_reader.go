@@ -7,7 +7,9 @@ package fig
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/ghodss/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
 	"io"
 )
 
@@ -24,21 +26,97 @@ func NewYamlLoader() *YamlLoader {
 }
 
 func (v *YamlReader) Read(r io.Reader) (*Value, error) {
+	ret, _, err := v.read(r, false)
+	return ret, err
+}
+
+func (v *YamlReader) ReadWithPosition(r io.Reader) (*Value, map[string]Position, error) {
+	return v.read(r, true)
+}
+
+func (v *YamlReader) read(r io.Reader, withPosition bool) (*Value, map[string]Position, error) {
 	buf := bytes.NewBuffer(nil)
 
 	_, err := io.Copy(buf, r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	ret := Value{}
 	logf("value: %s\n", buf.String())
-	err = yaml.Unmarshal(buf.Bytes(), &ret)
+	err = unmarshalYaml(buf.Bytes(), &ret)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &ret, nil
+	if !withPosition {
+		return &ret, nil, nil
+	}
+	// 位置信息仅用于Explain，解析失败时忽略
+	node := yamlv3.Node{}
+	pos := map[string]Position{}
+	if err := yamlv3.Unmarshal(buf.Bytes(), &node); err == nil {
+		yamlPositions(&node, "", Position{}, pos)
+	}
+	return &ret, pos, nil
+}
+
+// 先转换为json，数字使用json.Number保存原始文本
+func unmarshalYaml(data []byte, v interface{}) error {
+	j, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	return unmarshalJson(j, v)
+}
+
+func yamlPositions(n *yamlv3.Node, key string, pos Position, ret map[string]Position) {
+	switch n.Kind {
+	case yamlv3.DocumentNode:
+		if len(n.Content) > 0 {
+			yamlPositions(n.Content[0], key, pos, ret)
+		}
+	case yamlv3.AliasNode:
+		yamlPositions(n.Alias, key, pos, ret)
+	case yamlv3.MappingNode:
+		if len(n.Content) == 0 && key != "" {
+			ret[key] = pos
+			return
+		}
+		// 先处理合并的key（<<），使显式定义的key优先
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value != "<<" {
+				continue
+			}
+			m := n.Content[i+1]
+			if m.Kind == yamlv3.SequenceNode {
+				for j := len(m.Content) - 1; j >= 0; j-- {
+					yamlPositions(m.Content[j], key, pos, ret)
+				}
+			} else {
+				yamlPositions(m, key, pos, ret)
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			if k.Value == "<<" {
+				continue
+			}
+			yamlPositions(n.Content[i+1], joinKey(key, k.Value), Position{Line: k.Line, Column: k.Column}, ret)
+		}
+	case yamlv3.SequenceNode:
+		if len(n.Content) == 0 {
+			ret[key] = pos
+			return
+		}
+		for i, c := range n.Content {
+			yamlPositions(c, indexKey(key, i), Position{Line: c.Line, Column: c.Column}, ret)
+		}
+	default:
+		if key != "" {
+			ret[key] = pos
+		}
+	}
 }
 
 func (v *YamlLoader) Serialize(o interface{}) (string, error) {
@@ -49,3 +127,12 @@ func (v *YamlLoader) Serialize(o interface{}) (string, error) {
 func (v *YamlLoader) Deserialize(value string, result interface{}) error {
 	return yaml.Unmarshal([]byte(value), result)
 }
+
+func (v *YamlLoader) Decode(o interface{}, result interface{}) error {
+	return v.decodeHooks(o, result, nil)
+}
+
+func (v *YamlLoader) decodeHooks(o interface{}, result interface{}, hooks []decodeHook) error {
+	d := valueDecoder{scalarToString: true, hooks: hooks}
+	return d.decode(o, result)
+}